@@ -0,0 +1,14 @@
+//go:build !darwin && !linux
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// creationTime returns when path was created. There's no portable way to ask,
+// so the modification time stands in for it here.
+func creationTime(_ string, info os.FileInfo) time.Time {
+	return info.ModTime()
+}
@@ -2,35 +2,116 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"regexp/syntax"
+	"runtime"
+	"runtime/debug"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/muesli/termenv"
 )
 
+// version is set at build time with -ldflags "-X main.version=...", see the Justfile
+var version = "dev"
+
 // Configuration constants
 const (
-	version          = "0.2.1"
-	defaultShell     = "/bin/bash"
-	defaultTriesDir  = "src/tries"
-	configFileName   = "config"
-	configDirName    = ".config/try"
+	defaultShell    = "/bin/bash"
+	defaultTriesDir = "src/tries"
+	configFileName  = "config"
+	configDirName   = ".config/try"
+
+	defaultTodayName = "scratch"
+
+	// scratchDirName is the hidden area under the base path used by --scratch
+	scratchDirName = ".scratch"
+	// trashDirName is where deleted experiments go in each root, unless
+	// trash_dir names one place for all of them
+	trashDirName = ".trash"
+	// scratchKeepThreshold is how many files a scratch experiment may hold
+	// before it is kept (and moved into the base path) instead of removed
+	scratchKeepThreshold = 3
 )
 
 type Config struct {
-	Path  string `json:"path"`
-	Shell string `json:"shell,omitempty"`
+	Path         string            `json:"path"`
+	Paths        []string          `json:"paths,omitempty"` // Additional roots to browse; new experiments go to Path
+	Shell        string            `json:"shell,omitempty"`
+	IncludeFiles bool              `json:"include_files,omitempty"` // List regular files as single-file experiments
+	TodayName    string            `json:"today_name,omitempty"`    // Name of the daily `try today` experiment
+	Icons        map[string]string `json:"icons,omitempty"`         // Per-icon overrides, e.g. {"dir": ">"}
+	Sort         string            `json:"sort,omitempty"`          // Default sort order: score, name, created or accessed
+
+	ConfirmOutsideBase   bool   `json:"confirm_outside_base,omitempty"`   // Ask before entering or creating outside Path
+	RelativeTimeStyle    string `json:"relative_time_style,omitempty"`    // "terse" (3d ago, the default) or "natural" (yesterday)
+	ConfirmTimeout       int    `json:"confirm_timeout,omitempty"`        // Seconds before a pending delete confirmation is cancelled; 0 waits forever
+	DefaultGitignore     string `json:"default_gitignore,omitempty"`      // Built-in language, template file or inline content for new experiments
+	FilesystemDates      bool   `json:"filesystem_dates,omitempty"`       // Show the creation date from the filesystem instead of the name's date prefix
+	TouchOnAccess        *bool  `json:"touch_on_access,omitempty"`        // Bump an experiment's mtime when it's opened (default true)
+	NumberSelect         bool   `json:"number_select,omitempty"`          // Number the visible rows: 1-9 jump (empty search), Alt+1-9 select
+	EnterPrefersExisting bool   `json:"enter_prefers_existing,omitempty"` // Enter on the create row opens a top result whose name starts with the search
+	LoopAction           string `json:"loop_action,omitempty"`            // What --loop does with a pick: shell (default), editor, print or a command
+	ShellInitCommand     string `json:"shell_init_command,omitempty"`     // Run in each launched shell before it turns interactive
+	AgeColoring          bool   `json:"age_coloring,omitempty"`           // Tint names by how recently they were used
+	SearchMode           string `json:"search_mode,omitempty"`            // "always" (typing filters, the default) or "explicit" (press / to search)
+	ReadEnvrc            bool   `json:"read_envrc,omitempty"`             // Take TRY_PATH from the nearest .envrc when it isn't exported
+	CloneSubmodules      bool   `json:"clone_submodules,omitempty"`       // Also clone submodules (shallow, like the repository itself)
+	NameSeparator        string `json:"name_separator,omitempty"`         // Joins the date and the words of new names: "-" (default), "_" or "."
+	CloneGitUser         string `json:"clone_git_user,omitempty"`         // user.name set in each new clone; empty keeps the global one
+	CloneGitEmail        string `json:"clone_git_email,omitempty"`        // user.email set in each new clone; empty keeps the global one
+	CloneTimeoutSeconds  int    `json:"clone_timeout_seconds,omitempty"`  // Give up on a clone after this long; 0 means 120, negative never
+	CloneDepth           *int   `json:"clone_depth,omitempty"`            // Commits of history to clone, 0 for all (default 1)
+	PreviewSort          string `json:"preview_sort,omitempty"`           // Order of the preview pane's files: "name" (the default) or "mtime", newest first
+	PreviewShowSize      bool   `json:"preview_show_size,omitempty"`      // Show each file's size in the preview pane
+	InitGit              bool   `json:"init_git,omitempty"`               // Run git init in each new experiment
+	TemplateDir          string `json:"template_dir,omitempty"`           // Copied into each new experiment; --template picks a subdirectory
+	PostCreateHook       string `json:"post_create_hook,omitempty"`       // Run through the shell in each new experiment or clone, with $TRY_DIR set
+	TrashDir             string `json:"trash_dir,omitempty"`              // Where deleted experiments go; default .trash in their root
+	Editor               string `json:"editor,omitempty"`                 // Editor command, e.g. "code -w"; default $VISUAL, then $EDITOR
+	OpenInEditor         bool   `json:"open_in_editor,omitempty"`         // Open picked directories in the editor instead of a shell
+	PruneAfterDays       int    `json:"prune_after_days,omitempty"`       // --prune also removes experiments unchanged for this many days
+	CloneProtocol        string `json:"clone_protocol,omitempty"`         // "https" (the default) or "ssh" (git@host:owner/repo.git)
+	ShowGitStatus        bool   `json:"show_git_status,omitempty"`        // Show the branch of checkouts, with * when they have uncommitted changes
+
+	RunCommands map[string]string `json:"run_commands,omitempty"` // Project type to command, e.g. {"go": "go test ./..."}
+	Keybindings map[string]string `json:"keybindings,omitempty"`  // Action to comma-separated keys, e.g. {"delete": "ctrl+d,x"}
+	Aliases     map[string]string `json:"aliases,omitempty"`      // Short name to experiment name (or absolute path), e.g. {"nn": "2024-03-01-neural-net-v3"}
+	Bookmarks   []string          `json:"bookmarks,omitempty"`    // Experiment names (or absolute paths) shown in the bookmarks view
+	Pinned      []string          `json:"pinned,omitempty"`       // Experiment names (or absolute paths) listed above everything else
+	Scoring     *Scoring          `json:"scoring,omitempty"`      // Weights of the ranking, see scoreWeights
+	Profiles    map[string]Config `json:"profiles,omitempty"`     // Named path and shell sets picked with --profile, e.g. {"work": {"path": "~/work/tries"}}
+
+	// Only ever set from the command line, for one run
+	CloneBranch string `json:"-"` // Branch to check out in clones (--branch), cloned on its own
+	ForceClone  bool   `json:"-"` // Clone even when an experiment already has the repository (--force)
+}
+
+// Scoring overrides the weights the ranking is built from; unset ones keep
+// their defaults (see defaultScoreWeights)
+type Scoring struct {
+	DatePrefixBonus *float64 `json:"date_prefix_bonus,omitempty"` // Added for names starting with a date, 0 turns it off
+	CreatedWeight   *float64 `json:"created_weight,omitempty"`    // How much being created recently counts
+	AccessedWeight  *float64 `json:"accessed_weight,omitempty"`   // How much being used recently counts
+	LengthPenalty   *float64 `json:"length_penalty,omitempty"`    // Name length at which match points are halved
 }
 
 // sanitizePath validates and cleans a path to prevent path traversal attacks
@@ -70,6 +151,107 @@ func sanitizePath(path string) (string, error) {
 	return cleaned, nil
 }
 
+// keyActions are the selector actions that can be rebound, in display order
+var keyActions = []string{"select", "create", "delete", "run", "open_url", "root", "up", "down", "page_up", "page_down", "first", "last", "erase", "clear_search", "match_mode", "regex", "alias", "rename", "tools", "bookmark", "bookmarks", "pin", "mark", "undo", "trash", "paste", "preview", "sort", "quit"}
+
+// defaultKeybindings are the built-in keys for each action
+var defaultKeybindings = map[string]string{
+	"select":       "enter",
+	"create":       "ctrl+n",
+	"delete":       "ctrl+d,delete",
+	"run":          "ctrl+x",
+	"open_url":     "ctrl+g",
+	"root":         "ctrl+o",
+	"up":           "up,ctrl+p,ctrl+k",
+	"down":         "down,ctrl+j",
+	"page_up":      "pgup",
+	"page_down":    "pgdown",
+	"first":        "home",
+	"last":         "end",
+	"erase":        "backspace",
+	"clear_search": "ctrl+u",
+	"match_mode":   "ctrl+_,ctrl+/", // Most terminals send ctrl+/ as ctrl+_
+	"regex":        "ctrl+r",
+	"alias":        "ctrl+a",
+	"rename":       "ctrl+e",
+	"tools":        "ctrl+t",
+	"bookmark":     "ctrl+f",
+	"bookmarks":    "ctrl+b",
+	"pin":          "ctrl+y",
+	"mark":         "ctrl+@", // What terminals send for ctrl+space; plain space types into the search
+	"undo":         "ctrl+z",
+	"trash":        "ctrl+w",
+	"paste":        "ctrl+v",
+	"preview":      "tab",
+	"sort":         "ctrl+s",
+	"quit":         "esc,q,ctrl+c", // The first two are shown in the footer
+}
+
+// defaultKeymap maps each default key to its action
+var defaultKeymap, _ = buildKeymap(nil)
+
+// buildKeymap maps keys to actions, with overrides replacing an action's
+// default keys. An empty override unbinds the action. It fails on unknown
+// actions and on a key bound to more than one action.
+func buildKeymap(overrides map[string]string) (map[string]string, error) {
+	for action := range overrides {
+		if _, ok := defaultKeybindings[action]; !ok {
+			return nil, fmt.Errorf("unknown action %q (use %s)", action, strings.Join(keyActions, ", "))
+		}
+	}
+
+	keymap := make(map[string]string)
+	for _, action := range keyActions {
+		spec, ok := overrides[action]
+		if !ok {
+			spec = defaultKeybindings[action]
+		}
+		for _, key := range strings.Split(spec, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			if other, taken := keymap[key]; taken {
+				return nil, fmt.Errorf("key %q is bound to both %s and %s", key, other, action)
+			}
+			keymap[key] = action
+		}
+	}
+	return keymap, nil
+}
+
+// isValidAlias reports whether name can be used as an alias: a single word
+// that can be typed as a search
+func isValidAlias(name string) bool {
+	for _, char := range name {
+		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') ||
+			(char >= '0' && char <= '9') || char == '-' || char == '_' || char == '.') {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// resolveAlias returns the path an alias points to. Targets are experiment
+// names in the base path or absolute paths; missing targets don't resolve.
+func resolveAlias(config *Config, basePath, name string) (string, bool) {
+	if config == nil {
+		return "", false
+	}
+	target, ok := config.Aliases[name]
+	if !ok {
+		return "", false
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(basePath, target)
+	}
+	if _, err := os.Stat(target); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: alias %q points to missing %s\n", name, target)
+		return "", false
+	}
+	return target, true
+}
+
 // validateShell checks if a shell executable exists and is valid
 func validateShell(shell string) error {
 	if shell == "" {
@@ -99,41 +281,245 @@ func (c *Config) Validate() error {
 		c.Path = sanitized
 	}
 
+	for i, root := range c.Paths {
+		sanitized, err := sanitizePath(root)
+		if err != nil {
+			return fmt.Errorf("invalid path %q: %w", root, err)
+		}
+		c.Paths[i] = sanitized
+	}
+
+	if c.TrashDir != "" {
+		sanitized, err := sanitizePath(c.TrashDir)
+		if err != nil {
+			return fmt.Errorf("invalid trash_dir: %w", err)
+		}
+		c.TrashDir = sanitized
+		if err := validateTrashDir(c.TrashDir, append([]string{c.Path}, c.Paths...)); err != nil {
+			return err
+		}
+	}
+
+	if c.TemplateDir != "" {
+		sanitized, err := sanitizePath(c.TemplateDir)
+		if err != nil {
+			return fmt.Errorf("invalid template_dir: %w", err)
+		}
+		c.TemplateDir = sanitized
+	}
+
 	if c.Shell != "" {
 		if err := validateShell(c.Shell); err != nil {
 			return fmt.Errorf("invalid shell: %w", err)
 		}
 	}
 
+	for name, profile := range c.Profiles {
+		if len(profile.Profiles) > 0 {
+			return fmt.Errorf("invalid profile %q: profiles can't be nested", name)
+		}
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("invalid profile %q: %w", name, err)
+		}
+		c.Profiles[name] = profile
+	}
+
+	if c.Scoring != nil {
+		for _, weight := range []struct {
+			name  string
+			value *float64
+		}{
+			{"date_prefix_bonus", c.Scoring.DatePrefixBonus},
+			{"created_weight", c.Scoring.CreatedWeight},
+			{"accessed_weight", c.Scoring.AccessedWeight},
+		} {
+			if weight.value != nil && *weight.value < 0 {
+				return fmt.Errorf("invalid scoring.%s %g: must not be negative", weight.name, *weight.value)
+			}
+		}
+		if penalty := c.Scoring.LengthPenalty; penalty != nil && *penalty <= 0 {
+			return fmt.Errorf("invalid scoring.length_penalty %g: must be positive", *penalty)
+		}
+	}
+
+	if c.CloneDepth != nil && *c.CloneDepth < 0 {
+		return fmt.Errorf("invalid clone_depth %d: use 0 for the full history or a positive number of commits", *c.CloneDepth)
+	}
+
+	if c.ConfirmTimeout < 0 {
+		return fmt.Errorf("invalid confirm_timeout %d: must not be negative", c.ConfirmTimeout)
+	}
+
+	if c.PruneAfterDays < 0 {
+		return fmt.Errorf("invalid prune_after_days %d: must not be negative", c.PruneAfterDays)
+	}
+
+	switch c.CloneProtocol {
+	case "", "https", "ssh":
+	default:
+		return fmt.Errorf("invalid clone_protocol %q (use https or ssh)", c.CloneProtocol)
+	}
+
+	switch c.PreviewSort {
+	case "", "name", "mtime":
+	default:
+		return fmt.Errorf("invalid preview_sort %q (use name or mtime)", c.PreviewSort)
+	}
+
+	switch c.RelativeTimeStyle {
+	case "", "terse", "natural":
+	default:
+		return fmt.Errorf("invalid relative_time_style %q (use terse or natural)", c.RelativeTimeStyle)
+	}
+
+	for alias := range c.Aliases {
+		if !isValidAlias(alias) {
+			return fmt.Errorf("invalid alias %q: use letters, digits, '-', '_' and '.'", alias)
+		}
+	}
+
+	if c.NameSeparator != "" && !isValidNameSeparator(c.NameSeparator) {
+		return fmt.Errorf("invalid name_separator %q (use %s)", c.NameSeparator, strings.Join(nameSeparators, ", "))
+	}
+
+	switch c.SearchMode {
+	case "", "always", "explicit":
+	default:
+		return fmt.Errorf("invalid search_mode %q (use always or explicit)", c.SearchMode)
+	}
+
+	if _, err := buildKeymap(c.Keybindings); err != nil {
+		return fmt.Errorf("invalid keybindings: %w", err)
+	}
+
 	return nil
 }
 
+// tryEntry is one experiment; the JSON form is what --list --json prints
 type tryEntry struct {
-	Name     string
-	Basename string
-	Path     string
-	IsNew    bool
-	CTime    time.Time
-	MTime    time.Time
-	Score    float64
+	Name      string    `json:"name"`
+	Basename  string    `json:"basename"`
+	Path      string    `json:"path"`
+	IsNew     bool      `json:"-"`
+	IsFile    bool      `json:"is_file"`   // Single-file experiment, opened in $EDITOR
+	IsRepo    bool      `json:"is_repo"`   // Directory is a git checkout
+	Duplicate bool      `json:"duplicate"` // Another entry has the same name under a different date
+	Root      string    `json:"root"`      // Experiments root this entry was found in
+	CTime     time.Time `json:"ctime"`
+	MTime     time.Time `json:"mtime"`
+	Score     float64   `json:"score"`
+	Usage     *dirUsage `json:"-"` // Size on disk, nil until measured, see measureNext
+	Git       *gitState `json:"-"` // Branch and changes of a checkout with show_git_status, nil until read
+	Date      time.Time `json:"-"` // From the name's date prefix, zero without one
+	Trashed   bool      `json:"-"` // Listed from the trash with showTrash; Path is where it lies there
+	// With --grep, how many lines matched in the entry's files and the file with the most
+	Hits    int    `json:"hits,omitempty"`
+	HitFile string `json:"hit_file,omitempty"`
 }
 
 type model struct {
-	tries         []tryEntry
-	filteredTries []tryEntry
-	cursor        int
-	scrollOffset  int
-	searchTerm    string
-	selected      *selection
-	basePath      string
-	config        *Config
-	width         int
-	height        int
-	quitting      bool
-	inputMode     bool
-	newName       string
-	confirmDelete bool
-	deleteTarget  *tryEntry
+	tries          []tryEntry
+	filteredTries  []tryEntry
+	cursor         int
+	scrollOffset   int
+	searchTerm     string
+	query          string // searchTerm without kind tokens, used for matching and naming
+	only           string // Kind token in effect: "repo", "scratch" or ""
+	selected       *selection
+	basePath       string   // Primary root, where new experiments are created
+	roots          []string // All experiment roots, basePath first
+	source         Source   // Where entries come from; the roots on disk unless set
+	config         *Config
+	keys           map[string]string // Key to action, from keybindings; the defaults if nil
+	width          int
+	height         int
+	quitting       bool
+	inputMode      bool
+	aliasTarget    *tryEntry // Entry being given an alias, while its name is typed
+	aliasName      string
+	renameTarget   *tryEntry // Entry being renamed, while its new name is typed
+	renameName     string
+	metaTarget     *tryEntry      // Entry whose preferred shell and editor are being edited
+	metaDraft      experimentMeta // Values typed so far
+	metaField      int            // 0 while typing the shell, 1 for the editor
+	searchFocused  bool           // In explicit search mode, typing goes to the search box
+	showBookmarks  bool           // List only bookmarked experiments
+	showTrash      bool           // Also list what's in the trash, to restore it
+	pendingRestore *tryEntry      // Trashed entry awaiting confirmation to restore and enter it
+	since          time.Time      // With --since-last, only entries modified after this are listed
+	grep           *regexp.Regexp // With --grep, only entries with files matching this are listed
+	dates          dateMatcher    // Date tokens in the search like "jan" or "today", nil for none
+	loadErr        error          // Why the last loadTries came up short, e.g. an unreadable base path
+	hidePreview    bool           // Preview pane toggled off
+	previews       previewCache   // Reset when the list reloads
+	usage          usageCache     // Measured sizes by path, kept across reloads
+	measuring      bool           // A measureNext command is in flight
+	gitStates      gitCache       // Read git states by path, kept across reloads
+	trashed        []trashRecord  // Deleted this session, most recent last, for undo
+	existingClone  *tryEntry      // Already cloned from the URL being searched, see findClone
+	newName        string
+	runOnSelect    bool     // Enter runs the project's command instead of opening a shell
+	loop           bool     // Return to the list after acting on an entry
+	loopPrinted    []string // Paths picked with loop_action "print", written on exit
+	actions        []string // What this session did, e.g. "deleted foo", see printSessionSummary
+	confirmDelete  bool
+	deleteTarget   *tryEntry
+	deleteTyped    string     // With a typed confirmation, the name entered so far
+	deleteByName   bool       // The target must be confirmed by typing its name, see needsTypedConfirm
+	deleteBatch    []tryEntry // Marked entries being deleted together; deleteTarget is the first
+	confirmID      int        // Identifies the current confirmation for confirmTimeoutMsg
+	pendingSelect  *selection // Awaiting confirmation because it is outside basePath
+	matchMode      int
+	sortMode       string
+	marked         map[string]bool // Paths marked with ctrl+space for a batch delete, cleared when the search changes
+	searchRegex    *regexp.Regexp
+	regexErr       error
+	statusMsg      string // One-off notice shown above the help line
+	scorePrecision int    // Decimals in the score column, see scorePrecision
+	index          searchIndex
+}
+
+// searchIndex keeps what filterTries needs per entry so a keystroke doesn't
+// redo it for every experiment
+type searchIndex struct {
+	entries    []indexEntry // Parallel to model.tries
+	candidates []int        // Entries that matched query, see filterTries
+	query      string
+	scope      string // Kind token and view the candidates were found in
+}
+
+type indexEntry struct {
+	chars []rune // Lowercased basename
+	mask  uint64 // See runeMask
+}
+
+func buildIndex(tries []tryEntry) searchIndex {
+	entries := make([]indexEntry, len(tries))
+	for i, try := range tries {
+		chars := []rune(strings.ToLower(try.Basename))
+		entries[i] = indexEntry{chars: chars, mask: runeMask(chars)}
+	}
+	return searchIndex{entries: entries}
+}
+
+// runeMask sets one bit per ASCII letter and digit in chars. A name whose mask
+// lacks a bit of the query's can't match it; other characters aren't tracked.
+func runeMask(chars []rune) uint64 {
+	var mask uint64
+	for _, c := range chars {
+		switch {
+		case c >= 'a' && c <= 'z':
+			mask |= 1 << uint(c-'a')
+		case c >= '0' && c <= '9':
+			mask |= 1 << uint(26+c-'0')
+		}
+	}
+	return mask
+}
+
+// confirmTimeoutMsg cancels the confirmation it was scheduled for, if still pending
+type confirmTimeoutMsg struct {
+	id int
 }
 
 type selection struct {
@@ -173,6 +559,12 @@ var (
 	dateStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("240"))
 
+	// Age coloring buckets, brightest for today and fading with age
+	ageTodayStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "232", Dark: "231"}).Bold(true)
+	ageWeekStyle  = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "236", Dark: "252"})
+	ageMonthStyle = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "242", Dark: "247"})
+	ageOldStyle   = lipgloss.NewStyle().Foreground(lipgloss.AdaptiveColor{Light: "248", Dark: "240"})
+
 	separatorStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("237"))
 
@@ -194,16 +586,209 @@ var (
 			Foreground(lipgloss.Color("214"))
 )
 
+// Icon sets used throughout the TUI and banners. The emoji set is the default;
+// the ASCII set is for terminals or fonts without emoji support.
+var (
+	emojiIcons = map[string]string{
+		"dir":     "📁",
+		"file":    "📄",
+		"clone":   "📦",
+		"create":  "✨",
+		"enter":   "🚀",
+		"edit":    "📝",
+		"today":   "📅",
+		"scratch": "🧹",
+		"welcome": "🎉",
+		"star":    "⭐",
+		"pin":     "📌",
+		"mark":    "✅",
+		"trash":   "🚮",
+		"branch":  "⎇",
+		"success": "✅",
+		"warning": "⚠️ ",
+	}
+
+	asciiIcons = map[string]string{
+		"dir":     "[d]",
+		"file":    "[f]",
+		"clone":   "[git]",
+		"create":  "+",
+		"enter":   ">",
+		"edit":    "[e]",
+		"today":   "[t]",
+		"scratch": "[s]",
+		"welcome": "*",
+		"star":    "[*]",
+		"pin":     "[p]",
+		"mark":    "[x]",
+		"trash":   "[del]",
+		"branch":  "git:",
+		"success": "[ok]",
+		"warning": "[!]",
+	}
+
+	// icons is the active set, chosen at startup by setupIcons
+	icons = emojiIcons
+)
+
+func icon(name string) string {
+	return icons[name]
+}
+
+// setupIcons selects emoji or ASCII markers and applies config overrides.
+// TRY_ASCII=1 forces ASCII, TRY_ASCII=0 forces emoji; otherwise the
+// terminal and locale are used to guess.
+func setupIcons(config *Config, ascii bool) {
+	if !ascii {
+		switch strings.ToLower(os.Getenv("TRY_ASCII")) {
+		case "":
+			ascii = !terminalSupportsEmoji()
+		case "0", "false", "no":
+			ascii = false
+		default:
+			ascii = true
+		}
+	}
+
+	base := emojiIcons
+	if ascii {
+		base = asciiIcons
+	}
+	icons = make(map[string]string, len(base))
+	for name, value := range base {
+		icons[name] = value
+	}
+	if config != nil {
+		for name, value := range config.Icons {
+			icons[name] = value
+		}
+	}
+}
+
+// terminalSupportsEmoji guesses from TERM and the locale whether emoji will render
+func terminalSupportsEmoji() bool {
+	switch os.Getenv("TERM") {
+	case "linux", "dumb", "vt100", "vt220":
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := os.Getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	// No locale information, assume a modern terminal
+	return true
+}
+
 func getConfigPath() string {
-	// Always use ~/.config/try for consistency across platforms
-	// This avoids macOS Application Support restrictions and symlink issues
+	// An explicit location wins
+	if path := os.Getenv("TRY_CONFIG"); path != "" {
+		return path
+	}
+	// A fallback copy only exists because the usual location wasn't writable
+	if fallback := getFallbackConfigPath(); fallback != "" {
+		if _, err := os.Stat(fallback); err == nil {
+			return fallback
+		}
+	}
+	return getDefaultConfigPath()
+}
+
+// getDefaultConfigPath returns the usual config location: try/config under
+// $XDG_CONFIG_HOME when that is set, otherwise ~/.config/try/config on
+// Unix-like systems and %AppData%\try\config on Windows. A config that
+// already lives in ~/.config/try keeps being used, so setting
+// XDG_CONFIG_HOME (or upgrading on Windows) doesn't lose it.
+func getDefaultConfigPath() string {
+	// ~/.config/try rather than os.UserConfigDir(), which on macOS is
+	// Application Support with its restrictions and symlink issues
 	home, err := os.UserHomeDir()
 	if err != nil {
 		// If we can't find home, return empty string
 		// This will cause config operations to fail gracefully
 		return ""
 	}
-	return filepath.Join(home, ".config", "try", configFileName)
+	homeConfig := filepath.Join(home, ".config", "try", configFileName)
+
+	preferred := homeConfig
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		// The spec says relative values are to be ignored
+		preferred = filepath.Join(xdg, "try", configFileName)
+	} else if appData := os.Getenv("AppData"); runtime.GOOS == "windows" && appData != "" {
+		preferred = filepath.Join(appData, "try", configFileName)
+	}
+
+	if preferred != homeConfig {
+		if _, err := os.Stat(preferred); os.IsNotExist(err) {
+			if _, err := os.Stat(homeConfig); err == nil {
+				return homeConfig
+			}
+		}
+	}
+	return preferred
+}
+
+// getFallbackConfigPath returns where the config is saved when
+// ~/.config/try can't be written, e.g. because it is managed read-only
+func getFallbackConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".try", configFileName)
+}
+
+// errConfigReadOnly means no config location could be written; settings
+// then only come from the environment
+var errConfigReadOnly = errors.New("no writable config location")
+
+// fallbackNoted makes the note about using the fallback location appear once per run
+var fallbackNoted bool
+
+// writableConfigPath returns the config path to save to: the usual one, or
+// the fallback when the usual directory is read-only. TRY_CONFIG is used
+// as given, without a fallback.
+func writableConfigPath() (string, error) {
+	configPath := getConfigPath()
+	if configPath == "" {
+		return "", fmt.Errorf("cannot save config: home directory not found")
+	}
+	err := checkWritableDir(filepath.Dir(configPath))
+	if err == nil {
+		return configPath, nil
+	}
+	if os.Getenv("TRY_CONFIG") != "" {
+		return "", fmt.Errorf("%w: %s: %v", errConfigReadOnly, configPath, err)
+	}
+
+	fallback := getFallbackConfigPath()
+	if fallback == "" || fallback == configPath {
+		return "", fmt.Errorf("%w: %s: %v", errConfigReadOnly, configPath, err)
+	}
+	if fallbackErr := checkWritableDir(filepath.Dir(fallback)); fallbackErr != nil {
+		return "", fmt.Errorf("%w: tried %s and %s", errConfigReadOnly, configPath, fallback)
+	}
+	if !fallbackNoted {
+		fallbackNoted = true
+		fmt.Fprintf(os.Stderr, "Note: %s isn't writable, saving config to %s instead (set TRY_CONFIG to choose a location)\n", filepath.Dir(configPath), fallback)
+	}
+	return fallback, nil
+}
+
+// checkWritableDir creates dir if needed and checks a file can be created in it
+func checkWritableDir(dir string) error {
+	// Create config directory if it doesn't exist with restrictive permissions
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
 }
 
 // getLegacyConfigPaths returns old config locations for migration
@@ -306,6 +891,9 @@ func loadConfig() (*Config, error) {
 	if err := json.Unmarshal(data, &config); err != nil {
 		// Might be old format (plain text path)
 		path := strings.TrimSpace(string(data))
+		if !isLegacyPathConfig(path) {
+			return quarantineConfig(configPath, err), nil
+		}
 		if path != "" {
 			fmt.Fprintf(os.Stderr, "Note: Migrating config from old format to new JSON format\n")
 			return &Config{Path: path}, nil
@@ -316,16 +904,35 @@ func loadConfig() (*Config, error) {
 	return &config, nil
 }
 
-func saveConfig(config *Config) error {
-	configPath := getConfigPath()
-	if configPath == "" {
-		return fmt.Errorf("cannot save config: home directory not found")
+// isLegacyPathConfig reports whether an unparseable config file is the old
+// plain-text format: a single line holding the base path
+func isLegacyPathConfig(content string) bool {
+	return !strings.ContainsAny(content, "{}[]\"\n")
+}
+
+// quarantineConfig moves a config file that isn't valid JSON out of the way
+// and says so, rather than silently dropping its settings. The caller starts
+// from defaults, which runs onboarding again.
+func quarantineConfig(configPath string, parseErr error) *Config {
+	backupPath := configPath + ".bak"
+	if _, err := os.Stat(backupPath); err == nil {
+		// Don't overwrite an earlier backup
+		backupPath = fmt.Sprintf("%s.%d.bak", configPath, time.Now().Unix())
 	}
-	configDir := filepath.Dir(configPath)
 
-	// Create config directory if it doesn't exist with restrictive permissions
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	fmt.Fprintf(os.Stderr, "Warning: %s is not valid JSON: %v\n", configPath, parseErr)
+	if err := os.Rename(configPath, backupPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't back it up (%v); using defaults for this run\n", err)
+		return &Config{}
+	}
+	fmt.Fprintf(os.Stderr, "Moved it to %s and starting from defaults. Fix it and move it back to restore your settings.\n", backupPath)
+	return &Config{}
+}
+
+func saveConfig(config *Config) error {
+	configPath, err := writableConfigPath()
+	if err != nil {
+		return err
 	}
 
 	data, err := json.MarshalIndent(config, "", "  ")
@@ -333,13 +940,164 @@ func saveConfig(config *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, data, 0600); err != nil {
+	if err := writeFileAtomic(configPath, data, 0600); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// updateConfig applies change to the stored config (not env overrides) and
+// saves it, holding the config lock so concurrent instances don't lose each
+// other's updates
+func updateConfig(change func(*Config)) (*Config, error) {
+	configPath, err := writableConfigPath()
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockFile(configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	config, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	change(config)
+	if err := saveConfig(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never see a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, perm)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+	}
+	return err
+}
+
+// Lock file timing: how long to wait for another instance, and when a lock
+// left behind by a crashed one is considered abandoned
+const (
+	lockWait  = 3 * time.Second
+	lockStale = 30 * time.Second
+)
+
+// lockFile takes an exclusive lock on path by creating path.lock. Exclusive
+// create works the same on every platform, unlike flock. The returned
+// function releases the lock.
+func lockFile(path string) (func(), error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockWait)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > lockStale {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for %s (remove it if no other try is running)", lockPath)
+		}
+		time.Sleep(25 * time.Millisecond)
+	}
+}
+
+// envrcTryPath looks for TRY_PATH in the nearest .envrc above the working
+// directory, for shells without the direnv hook. Only plain assignments like
+// `export TRY_PATH=~/tries` are understood; nothing is evaluated beyond
+// $VAR expansion, where $PWD is the .envrc's directory.
+func envrcTryPath() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		data, err := os.ReadFile(filepath.Join(dir, ".envrc"))
+		if err == nil {
+			// Like direnv, only the nearest .envrc counts
+			return parseEnvrcTryPath(string(data), dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// parseEnvrcTryPath returns the last TRY_PATH assigned in an .envrc found in dir
+func parseEnvrcTryPath(content, dir string) string {
+	value := ""
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "export ")
+		if !strings.HasPrefix(line, "TRY_PATH=") {
+			continue
+		}
+		raw := strings.TrimPrefix(line, "TRY_PATH=")
+
+		if len(raw) >= 2 && raw[0] == '\'' && raw[len(raw)-1] == '\'' {
+			// Single quotes are literal
+			value = raw[1 : len(raw)-1]
+			continue
+		}
+		if len(raw) >= 2 && raw[0] == '"' && raw[len(raw)-1] == '"' {
+			raw = raw[1 : len(raw)-1]
+		} else if i := strings.Index(raw, " #"); i >= 0 {
+			raw = strings.TrimSpace(raw[:i])
+		}
+		value = os.Expand(raw, func(name string) string {
+			if name == "PWD" {
+				return dir
+			}
+			return os.Getenv(name)
+		})
+	}
+
+	// Relative roots are relative to the .envrc, as they would be under direnv
+	roots := filepath.SplitList(value)
+	for i, root := range roots {
+		if root != "" && !filepath.IsAbs(root) && !strings.HasPrefix(root, "~") {
+			roots[i] = filepath.Join(dir, root)
+		}
+	}
+	return strings.Join(roots, string(os.PathListSeparator))
+}
+
 // getResolvedConfig loads config and applies environment variable overrides
 func getResolvedConfig() (*Config, error) {
 	// Always load config first
@@ -349,12 +1107,34 @@ func getResolvedConfig() (*Config, error) {
 	}
 
 	// Apply environment variable overrides
-	if tryPath := os.Getenv("TRY_PATH"); tryPath != "" {
-		config.Path = tryPath
+	tryPath := os.Getenv("TRY_PATH")
+	if tryPath == "" && config.ReadEnvrc {
+		tryPath = envrcTryPath()
+	}
+	if tryPath != "" {
+		// A list of roots replaces the configured ones; the first is primary
+		roots := filepath.SplitList(tryPath)
+		config.Path = roots[0]
+		config.Paths = roots[1:]
 	}
 
 	if tryShell := os.Getenv("TRY_SHELL"); tryShell != "" {
-		config.Shell = tryShell
+		// Resolved through PATH like a shell picked during setup, so
+		// TRY_SHELL=zsh works. A bad override shouldn't stop try from
+		// starting; the configured shell or $SHELL still works
+		if shellPath, err := resolveShellPath(tryShell); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring TRY_SHELL: %v\n", err)
+		} else {
+			config.Shell = shellPath
+		}
+	}
+
+	if timeout := os.Getenv("TRY_CLONE_TIMEOUT"); timeout != "" {
+		if seconds, err := strconv.Atoi(timeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring TRY_CLONE_TIMEOUT: %q isn't a number of seconds\n", timeout)
+		} else {
+			config.CloneTimeoutSeconds = seconds
+		}
 	}
 
 	// Validate and sanitize the final config
@@ -374,6 +1154,47 @@ func getDefaultPath(config *Config) string {
 	return ""
 }
 
+// applyProfile replaces the path and shell with those of the named profile,
+// keeping the top-level ones for whatever the profile leaves out
+func applyProfile(config *Config, name string) {
+	profile, ok := config.Profiles[name]
+	if !ok {
+		if len(config.Profiles) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: unknown profile %q (the config file has no profiles)\n", name)
+		} else {
+			names := make([]string, 0, len(config.Profiles))
+			for name := range config.Profiles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Fprintf(os.Stderr, "Error: unknown profile %q (use %s)\n", name, strings.Join(names, ", "))
+		}
+		os.Exit(1)
+	}
+	if profile.Path != "" {
+		config.Path = profile.Path
+		config.Paths = profile.Paths
+	}
+	if profile.Shell != "" {
+		config.Shell = profile.Shell
+	}
+}
+
+// getRoots returns every experiments root, primary first and without duplicates
+func getRoots(config *Config, primary string) []string {
+	roots := []string{primary}
+	seen := map[string]bool{primary: true}
+	if config != nil {
+		for _, root := range config.Paths {
+			if root != "" && !seen[root] {
+				seen[root] = true
+				roots = append(roots, root)
+			}
+		}
+	}
+	return roots
+}
+
 func getShell(config *Config) string {
 	// Config has already been resolved with environment variable overrides
 	if config != nil && config.Shell != "" {
@@ -389,115 +1210,455 @@ func getShell(config *Config) string {
 	return defaultShell
 }
 
-func promptForPath() string {
-	home, _ := os.UserHomeDir()
-	defaultPath := filepath.Join(home, defaultTriesDir)
+// touchPath bumps path's timestamps so recently used experiments rank higher,
+// unless touch_on_access is off. Failures only warn, and not in select-only
+// mode where stderr belongs to the calling script.
+func touchPath(path string, config *Config, quiet bool) {
+	if config != nil && config.TouchOnAccess != nil && !*config.TouchOnAccess {
+		return
+	}
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil && !quiet {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't update access time: %v\n", err)
+	}
+}
 
-	fmt.Println(titleStyle.Render("🎉 Welcome to Try!"))
-	fmt.Println()
-	fmt.Println("Try needs a directory to store your experiments.")
-	fmt.Println("This will be created if it doesn't exist.")
-	fmt.Println()
-	fmt.Printf("%s [%s]: ",
-		promptStyle.Render("Where should experiments be stored?"),
-		dimStyle.Render(defaultPath))
+// launchShell starts an interactive shell in dir and waits for it to exit
+func launchShell(dir string, config *Config) error {
+	cmd, cleanup := shellCommand(dir, config)
+	defer cleanup()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
 
-	// Read the full line of input (allows spaces in paths)
-	reader := bufio.NewReader(os.Stdin)
-	input, err := reader.ReadString('\n')
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "\nError reading input: %v\n", err)
+// exitAfterShell ends try the way the interactive shell ended. A shell that
+// ran and exited non-zero is normal, so its status is passed on quietly; a
+// shell that couldn't be started at all is reported as a setup problem.
+func exitAfterShell(err error, config *Config) {
+	if err == nil {
+		return
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		code := exitErr.ExitCode()
+		if code < 0 {
+			// Killed by a signal
+			code = 1
+		}
+		os.Exit(code)
+	}
+	fmt.Fprintf(os.Stderr, "Error: couldn't start shell %s: %v\n", getShell(config), err)
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Op != "chdir" {
+		// The directory is fine, so it's most likely the configured shell
+		fmt.Fprintf(os.Stderr, "Check the shell setting (TRY_SHELL or \"shell\" in %s)\n", getConfigPath())
+	}
+	os.Exit(1)
+}
+
+// shellCommand builds the interactive shell for dir, preferring the shell
+// named in its .try-meta. With shell_init_command
+// set, the shell runs it first and then stays interactive; how that's done
+// depends on the shell. cleanup removes any temporary files once the shell
+// has exited.
+func shellCommand(dir string, config *Config) (*exec.Cmd, func()) {
+	shell := getShell(config)
+	if preferred := readMeta(dir).Shell; preferred != "" {
+		if path, err := exec.LookPath(preferred); err == nil {
+			shell = path
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s shell %q not found, using %s\n", metaFileName, preferred, shell)
+		}
+	}
+	cleanup := func() {}
+	initCommand := ""
+	if config != nil {
+		initCommand = config.ShellInitCommand
+	}
+
+	var cmd *exec.Cmd
+	switch name := strings.TrimSuffix(filepath.Base(shell), ".exe"); {
+	case initCommand == "":
+		cmd = exec.Command(shell)
+	case name == "fish":
+		// fish has a flag for exactly this
+		cmd = exec.Command(shell, "-C", initCommand)
+	case name == "bash":
+		// A throwaway rcfile that still loads the user's own
+		rc, err := os.CreateTemp("", "try-bashrc-*")
+		if err == nil {
+			fmt.Fprintf(rc, "[ -f ~/.bashrc ] && . ~/.bashrc\n%s\n", initCommand)
+			rc.Close()
+			cmd = exec.Command(shell, "--rcfile", rc.Name(), "-i")
+			cleanup = func() { os.Remove(rc.Name()) }
+			break
+		}
+		fallthrough
+	default:
+		// Run the command, then replace it with an interactive shell. Environment
+		// changes carry over; shell-local state like aliases doesn't.
+		cmd = exec.Command(shell, "-c", initCommand+"; exec "+shellQuote(shell)+" -i")
+	}
+	cmd.Dir = dir
+	return cmd, cleanup
+}
+
+// shellQuote single-quotes s for POSIX shells
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runInShell runs command through the user's shell in dir and waits for it
+func runInShell(dir, command string, config *Config) error {
+	cmd := exec.Command(getShell(config), "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Dir = dir
+	return cmd.Run()
+}
+
+// runPostCreateHook runs post_create_hook in a new experiment, with TRY_DIR
+// set to its path. A failing hook is only reported, so the experiment is
+// still entered. In select-only mode the hook's output goes to stderr, leaving
+// stdout to the printed path.
+func runPostCreateHook(dir string, config *Config, selectOnly bool) {
+	if config == nil || config.PostCreateHook == "" {
+		return
+	}
+	cmd := exec.Command(getShell(config), "-c", config.PostCreateHook)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "TRY_DIR="+dir)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	if selectOnly {
+		cmd.Stdout = os.Stderr
+	}
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: post_create_hook failed: %v\n", err)
+	}
+}
+
+// Marker files identifying a project type, checked in order
+var projectMarkers = []struct {
+	file string
+	kind string
+}{
+	{"go.mod", "go"},
+	{"Cargo.toml", "rust"},
+	{"package.json", "node"},
+	{"pyproject.toml", "python"},
+	{"requirements.txt", "python"},
+	{"setup.py", "python"},
+	{"Gemfile", "ruby"},
+	{"Makefile", "make"},
+}
+
+// detectProjectType names the kind of project in dir, or "" if unrecognised
+func detectProjectType(dir string) string {
+	for _, marker := range projectMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker.file)); err == nil {
+			return marker.kind
+		}
+	}
+	return ""
+}
+
+// runProjectCommand runs the configured command for dir's project type
+func runProjectCommand(dir string, config *Config) {
+	kind := detectProjectType(dir)
+	if kind == "" {
+		fmt.Fprintf(os.Stderr, "Error: couldn't detect the project type of %s\n", filepath.Base(dir))
+		os.Exit(1)
+	}
+	command := config.RunCommands[kind]
+	if command == "" {
+		fmt.Fprintf(os.Stderr, "Error: no run command configured for %s projects (set run_commands.%s in %s)\n", kind, kind, getConfigPath())
 		os.Exit(1)
 	}
-	input = strings.TrimSpace(input)
 
-	// Use default if empty
-	if input == "" {
-		input = defaultPath
+	fmt.Fprintf(os.Stderr, "\n%s Running %q in %s\n\n", icon("enter"), command, filepath.Base(dir))
+
+	if err := runInShell(dir, command, config); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s failed: %v\n", command, err)
+		os.Exit(1)
 	}
+}
 
-	// Sanitize the path
-	absPath, err := sanitizePath(input)
+// getEditor returns the editor command from the config, $VISUAL or $EDITOR,
+// falling back to vi
+func getEditor(config *Config) string {
+	if config != nil && config.Editor != "" {
+		return config.Editor
+	}
+	if editor := os.Getenv("VISUAL"); editor != "" {
+		return editor
+	}
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// launchEditor opens path in the user's editor and waits for it to exit
+func launchEditor(path string, config *Config) error {
+	cmd, err := editorCommand(path, config)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
+		return err
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// editorCommand builds the command that opens path in the user's editor, or
+// the one a directory's .try-meta asks for
+func editorCommand(path string, config *Config) (*exec.Cmd, error) {
+	editor := getEditor(config)
+	if preferred := strings.TrimSpace(readMeta(path).Editor); preferred != "" {
+		editor = preferred
+	}
+	// The editor may carry its own arguments, e.g. "code -w"
+	parts := strings.Fields(editor)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("editor command %q is empty", editor)
+	}
+	cmd := exec.Command(parts[0], append(parts[1:], path)...)
+	cmd.Dir = filepath.Dir(path)
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		cmd.Dir = path
+	}
+	return cmd, nil
+}
+
+// metaFileName is the per-experiment preferences file inside an experiment
+const metaFileName = ".try-meta"
+
+// experimentMeta holds the preferences stored in an experiment's .try-meta
+type experimentMeta struct {
+	Shell  string `json:"shell,omitempty"`  // Shell to open instead of the global one, a name or path
+	Editor string `json:"editor,omitempty"` // Editor command to open the experiment with, e.g. "code -w"
+}
+
+// readMeta loads dir's .try-meta; a missing or unreadable file means no preferences
+func readMeta(dir string) experimentMeta {
+	var meta experimentMeta
+	data, err := os.ReadFile(filepath.Join(dir, metaFileName))
+	if err != nil {
+		return meta
+	}
+	if err := json.Unmarshal(data, &meta); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring invalid %s in %s: %v\n", metaFileName, filepath.Base(dir), err)
+		return experimentMeta{}
+	}
+	return meta
+}
+
+// writeMeta saves meta to dir's .try-meta, removing the file once it's empty
+func writeMeta(dir string, meta experimentMeta) error {
+	path := filepath.Join(dir, metaFileName)
+	if meta == (experimentMeta{}) {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0644)
+}
+
+// requireBasePath returns the configured base path, running onboarding if none is set yet
+func requireBasePath(config *Config) (string, *Config) {
+	basePath := getDefaultPath(config)
+	if basePath != "" {
+		return basePath, config
+	}
+
+	basePath = promptForPath()
+	// Reload config after prompting
+	config, err := getResolvedConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to reload config after setting path: %v\n", err)
 		os.Exit(1)
 	}
+	return basePath, config
+}
 
-	config := &Config{Path: absPath}
+// resolveShellPath finds a shell executable by name or path and returns its absolute path
+func resolveShellPath(shell string) (string, error) {
+	shellPath, err := exec.LookPath(shell)
+	if err != nil {
+		return "", fmt.Errorf("shell '%s' not found", shell)
+	}
+	// Make absolute if it's not already
+	if !filepath.IsAbs(shellPath) {
+		shellPath, err = filepath.Abs(shellPath)
+		if err != nil {
+			return "", fmt.Errorf("cannot resolve shell path: %w", err)
+		}
+	}
+	return shellPath, nil
+}
 
-	// Now prompt for shell configuration
-	fmt.Println()
-	fmt.Println(promptStyle.Render("Shell Configuration (optional)"))
+// promptForShell asks for an optional shell override and records it in config
+func promptForShell(reader *bufio.Reader, current, config *Config) {
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, promptStyle.Render("Shell Configuration (optional)"))
 	currentShell := os.Getenv("SHELL")
 	if currentShell == "" {
 		currentShell = defaultShell
 	}
-	fmt.Printf("Current SHELL: %s\n", dimStyle.Render(currentShell))
-	fmt.Print("Override shell (press Enter to use $SHELL): ")
-	
+	fmt.Fprintf(os.Stderr, "Current SHELL: %s\n", dimStyle.Render(currentShell))
+	if current.Shell != "" {
+		fmt.Fprintf(os.Stderr, "Override shell (Enter keeps %s, - uses $SHELL): ", dimStyle.Render(current.Shell))
+	} else {
+		fmt.Fprint(os.Stderr, "Override shell (press Enter to use $SHELL): ")
+	}
+
 	shellInput, err := reader.ReadString('\n')
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "\nError reading input: %v\n", err)
 		// Don't exit, just use default
+		return
+	}
+	shellInput = strings.TrimSpace(shellInput)
+	if shellInput == "-" {
+		config.Shell = ""
+	} else if shellInput != "" {
+		shellPath, err := resolveShellPath(shellInput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v, using $SHELL\n", icon("warning"), err)
+		} else {
+			config.Shell = shellPath
+			fmt.Fprintf(os.Stderr, "%s Shell set to: %s\n", icon("success"), createNewStyle.Render(shellPath))
+		}
+	}
+}
+
+// assumeYes makes onboarding take the defaults without asking or pausing, for --yes
+var assumeYes bool
+
+func promptForPath() string {
+	return runSetup(&Config{}, true)
+}
+
+// runSetup walks through the path and shell questions, offering current's
+// values as the defaults, and saves the answers without touching any other
+// settings. firstRun selects the welcome wording and the final pause before
+// the selector opens.
+func runSetup(current *Config, firstRun bool) string {
+	home, _ := os.UserHomeDir()
+	defaultPath := filepath.Join(home, defaultTriesDir)
+	if current.Path != "" {
+		defaultPath = current.Path
+	}
+
+	if firstRun {
+		fmt.Fprintln(os.Stderr, titleStyle.Render(icon("welcome")+" Welcome to Try!"))
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Try needs a directory to store your experiments.")
 	} else {
-		shellInput = strings.TrimSpace(shellInput)
-		if shellInput != "" {
-			// Find the shell executable
-			shellPath, err := exec.LookPath(shellInput)
-			if err != nil {
-				fmt.Printf("⚠️  Shell '%s' not found, using $SHELL\n", shellInput)
-			} else {
-				// Make absolute if it's not already
-				if !filepath.IsAbs(shellPath) {
-					shellPath, err = filepath.Abs(shellPath)
-					if err != nil {
-						fmt.Printf("⚠️  Cannot resolve shell path: %v\n", err)
-						shellPath = ""
-					}
-				}
-				if shellPath != "" {
-					config.Shell = shellPath
-					fmt.Printf("✅ Shell set to: %s\n", createNewStyle.Render(shellPath))
-				}
-			}
+		fmt.Fprintln(os.Stderr, titleStyle.Render(icon("welcome")+" Try Setup"))
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprintln(os.Stderr, "Press Enter to keep a current value.")
+	}
+	fmt.Fprintln(os.Stderr, "This will be created if it doesn't exist.")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "%s [%s]: ",
+		promptStyle.Render("Where should experiments be stored?"),
+		dimStyle.Render(defaultPath))
+
+	// Read the full line of input (allows spaces in paths)
+	reader := bufio.NewReader(os.Stdin)
+	input := ""
+	if assumeYes {
+		fmt.Fprintln(os.Stderr)
+	} else {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "\nError reading input: %v\n", err)
+			os.Exit(1)
 		}
+		input = strings.TrimSpace(line)
 	}
 
-	// Store config
-	if err := saveConfig(config); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	// Use default if empty
+	if input == "" {
+		input = defaultPath
+	}
+
+	// Sanitize the path
+	absPath, err := sanitizePath(input)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
+		os.Exit(1)
+	}
+
+	config := &Config{Path: absPath, Shell: current.Shell}
+
+	// Now prompt for shell configuration; --yes keeps the current choice
+	if !assumeYes {
+		promptForShell(reader, current, config)
+	}
+
+	// Store config, keeping anything else already in the file
+	_, saveErr := updateConfig(func(c *Config) {
+		c.Path = config.Path
+		c.Shell = config.Shell
+	})
+	if errors.Is(saveErr, errConfigReadOnly) {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", saveErr)
+		fmt.Fprintln(os.Stderr, "These choices only last for this run. Set TRY_PATH (and TRY_SHELL) in your shell profile, or point TRY_CONFIG at a writable file.")
+	} else if saveErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", saveErr)
 	}
 
 	// Show success message
-	fmt.Println()
-	fmt.Printf("✅ Experiments will be stored in: %s\n", createNewStyle.Render(absPath))
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintf(os.Stderr, "%s Experiments will be stored in: %s\n", icon("success"), createNewStyle.Render(absPath))
 	if config.Shell != "" {
-		fmt.Printf("✅ Shell override: %s\n", createNewStyle.Render(config.Shell))
+		fmt.Fprintf(os.Stderr, "%s Shell override: %s\n", icon("success"), createNewStyle.Render(config.Shell))
+	}
+	if saveErr == nil {
+		fmt.Fprintf(os.Stderr, "%s\n", dimStyle.Render(fmt.Sprintf("(You can change these settings with try setup or by editing %s)", getConfigPath())))
 	}
-	fmt.Printf("%s\n", dimStyle.Render(fmt.Sprintf("(You can change these settings by editing %s)", getConfigPath())))
-	fmt.Println()
 
-	// Wait for user to acknowledge
-	fmt.Print(helpStyle.Render("Press Enter to continue..."))
-	bufio.NewReader(os.Stdin).ReadString('\n')
+	if firstRun && !assumeYes {
+		// Wait for user to acknowledge
+		fmt.Fprintln(os.Stderr)
+		fmt.Fprint(os.Stderr, helpStyle.Render("Press Enter to continue..."))
+		bufio.NewReader(os.Stdin).ReadString('\n')
+	}
 
 	return absPath
 }
 
-func initialModel(searchTerm string, config *Config) model {
-	basePath := getDefaultPath(config)
+// handleSetup re-runs the onboarding questions for an existing install
+func handleSetup() {
+	// Pre-fill from the stored config rather than env overrides
+	config, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+	setupIcons(config, false)
+	path := runSetup(config, false)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating directory %s: %v\n", path, err)
+		os.Exit(1)
+	}
+}
 
+func initialModel(searchTerm string, config *Config) model {
 	// If no path configured, prompt for it
-	if basePath == "" {
-		basePath = promptForPath()
-		// Reload config after prompting
-		var err error
-		config, err = getResolvedConfig()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to reload config after setting path: %v\n", err)
-			os.Exit(1)
-		}
-	}
+	basePath, config := requireBasePath(config)
 
 	// Ensure base path exists
 	if err := os.MkdirAll(basePath, 0755); err != nil {
@@ -505,28 +1666,150 @@ func initialModel(searchTerm string, config *Config) model {
 	}
 
 	m := model{
-		searchTerm: strings.ReplaceAll(searchTerm, " ", "-"),
+		searchTerm: searchTerm,
 		basePath:   basePath,
+		roots:      getRoots(config, basePath),
 		config:     config,
+		sortMode:   config.Sort,
 		width:      80,
 		height:     24,
 	}
+	// Validated with the config, so an error can't happen here
+	m.keys, _ = buildKeymap(config.Keybindings)
 
 	m.loadTries()
 	m.filterTries()
 	return m
 }
 
+// Source supplies the entries the selector browses
+type Source interface {
+	List() ([]tryEntry, error)
+}
+
+// dirSource lists the experiments directly inside each root directory
+type dirSource struct {
+	roots        []string
+	includeFiles bool // Also list regular files as single-file experiments
+}
+
 func (m *model) loadTries() {
-	m.tries = []tryEntry{}
+	if m.source == nil {
+		roots := m.roots
+		if len(roots) == 0 {
+			roots = []string{m.basePath}
+		}
+		m.source = dirSource{
+			roots:        roots,
+			includeFiles: m.config != nil && m.config.IncludeFiles,
+		}
+	}
 
-	entries, err := os.ReadDir(m.basePath)
+	tries, err := m.source.List()
+	m.loadErr = err
+	var rootErr *rootError
+	if err != nil && (len(tries) > 0 || !errors.As(err, &rootErr)) {
+		// With nothing listed, View explains a bad base path in place of the list instead
+		m.statusMsg = fmt.Sprintf("Couldn't load experiments: %v", err)
+	}
+	if m.showTrash {
+		tries = append(tries, m.trashedTries()...)
+	}
+	if m.grep != nil {
+		tries = grepTries(tries, m.grep)
+	}
+	if tries == nil {
+		tries = []tryEntry{}
+	}
+	m.tries = tries
+
+	m.markDuplicates()
+	m.index = buildIndex(m.tries)
+	m.previews = previewCache{}
+	if m.usage == nil {
+		m.usage = usageCache{}
+	}
+	sep := nameSeparator(m.config)
+	for i := range m.tries {
+		if usage, ok := m.usage[m.tries[i].Path]; ok {
+			m.tries[i].Usage = &usage
+		}
+		if state, ok := m.gitStates[m.tries[i].Path]; ok {
+			m.tries[i].Git = &state
+		}
+		if datePart, _, ok := splitDatePrefix(m.tries[i].Basename, sep); ok {
+			m.tries[i].Date, _ = time.ParseInLocation("2006-01-02", datePart, time.Local)
+		}
+	}
+}
+
+// List scans every root. Unreadable extra roots are skipped; an unreadable
+// primary root is reported as a *rootError, with the other roots still listed.
+func (s dirSource) List() ([]tryEntry, error) {
+	var tries []tryEntry
+	var primaryErr error
+	for i, root := range s.roots {
+		entries, err := s.listRoot(root)
+		if err != nil && i == 0 {
+			primaryErr = describeRootError(root, err)
+		}
+		tries = append(tries, entries...)
+	}
+	return tries, primaryErr
+}
+
+// rootError explains why the base path couldn't be read and what to do about it
+type rootError struct {
+	Root    string
+	Problem string // What's wrong, e.g. "~/tries is a file, not a directory"
+	Hint    string // How to fix it
+}
+
+func (e *rootError) Error() string {
+	return e.Problem
+}
+
+// describeRootError turns the error from reading root into a rootError for
+// the cases people actually run into: a missing directory, a broken symlink,
+// a file in its place and missing permissions
+func describeRootError(root string, err error) error {
+	moveIt := "point TRY_PATH or the config's path at your experiments (try setup changes it)"
+	if info, lerr := os.Lstat(root); lerr == nil && info.Mode()&os.ModeSymlink != 0 {
+		if _, serr := os.Stat(root); serr != nil {
+			target, _ := os.Readlink(root)
+			return &rootError{
+				Root:    root,
+				Problem: fmt.Sprintf("%s is a broken symlink to %s", root, target),
+				Hint:    "Recreate the link's target, or " + moveIt,
+			}
+		}
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return &rootError{Root: root, Problem: root + " doesn't exist", Hint: "Create it, or " + moveIt}
+	case errors.Is(err, fs.ErrPermission):
+		return &rootError{Root: root, Problem: "Permission denied reading " + root, Hint: "Check its permissions, e.g. chmod u+rwx " + root}
+	}
+	if info, serr := os.Stat(root); serr == nil && !info.IsDir() {
+		return &rootError{Root: root, Problem: root + " is a file, not a directory", Hint: "Move the file away, or " + moveIt}
+	}
+	return &rootError{Root: root, Problem: fmt.Sprintf("Can't read %s: %v", root, err), Hint: "Check the path, or " + moveIt}
+}
+
+// listRoot returns the experiments found directly inside root
+func (s dirSource) listRoot(root string) ([]tryEntry, error) {
+	entries, err := os.ReadDir(root)
 	if err != nil {
-		return
+		return nil, err
 	}
 
+	var tries []tryEntry
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		if entry.Name() == scratchDirName || entry.Name() == trashDirName {
+			continue
+		}
+		isFile := entry.Type().IsRegular()
+		if !entry.IsDir() && !(s.includeFiles && isFile) {
 			continue
 		}
 
@@ -535,109 +1818,616 @@ func (m *model) loadTries() {
 			continue
 		}
 
-		path := filepath.Join(m.basePath, entry.Name())
+		path := filepath.Join(root, entry.Name())
 		stat, _ := os.Stat(path)
 
-		m.tries = append(m.tries, tryEntry{
+		// A .git directory (or a worktree's .git file) marks a checkout
+		isRepo := false
+		if !isFile {
+			_, err := os.Stat(filepath.Join(path, ".git"))
+			isRepo = err == nil
+		}
+
+		tries = append(tries, tryEntry{
 			Name:     entry.Name(),
 			Basename: entry.Name(),
 			Path:     path,
 			IsNew:    false,
-			CTime:    info.ModTime(), // Go doesn't have creation time on all platforms
+			IsFile:   isFile,
+			IsRepo:   isRepo,
+			CTime:    creationTime(path, info),
 			MTime:    stat.ModTime(),
+			Root:     root,
 		})
 	}
+	return tries, nil
 }
 
-func (m *model) filterTries() {
-	m.filteredTries = []tryEntry{}
+// fileSource lists the paths named in a newline-delimited file, one per line.
+// Blank lines and lines starting with # are ignored.
+type fileSource struct {
+	path    string
+	warned  bool // Missing paths are reported on the first load only
+	skipped int  // Paths skipped on the first load
+}
 
-	for _, try := range m.tries {
-		score := m.calculateScore(try)
-		try.Score = score
+func (s *fileSource) List() ([]tryEntry, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
 
-		if m.searchTerm == "" || score > 0 {
-			m.filteredTries = append(m.filteredTries, try)
+	quiet := s.warned
+	s.warned = true
+
+	var tries []tryEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		path, err := sanitizePath(line)
+		var info os.FileInfo
+		if err == nil {
+			info, err = os.Stat(path)
+		}
+		if err != nil {
+			if !quiet {
+				if os.IsNotExist(err) {
+					fmt.Fprintf(os.Stderr, "Warning: skipping missing path %s\n", line)
+				} else {
+					fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", line, err)
+				}
+				s.skipped++
+			}
+			continue
 		}
+
+		isFile := info.Mode().IsRegular()
+		isRepo := false
+		if !isFile {
+			_, err := os.Stat(filepath.Join(path, ".git"))
+			isRepo = err == nil
+		}
+
+		name := filepath.Base(path)
+		tries = append(tries, tryEntry{
+			Name:     name,
+			Basename: name,
+			Path:     path,
+			IsFile:   isFile,
+			IsRepo:   isRepo,
+			CTime:    creationTime(path, info),
+			MTime:    info.ModTime(),
+			Root:     filepath.Dir(path),
+		})
 	}
+	return tries, nil
+}
 
-	// Sort by score descending
-	sort.Slice(m.filteredTries, func(i, j int) bool {
-		return m.filteredTries[i].Score > m.filteredTries[j].Score
-	})
+// markDuplicates flags entries whose name, ignoring the date prefix, is shared with another entry
+func (m *model) markDuplicates() {
+	sep := nameSeparator(m.config)
+	counts := make(map[string]int, len(m.tries))
+	for _, try := range m.tries {
+		counts[undatedName(try.Basename, sep)]++
+	}
+	for i := range m.tries {
+		m.tries[i].Duplicate = counts[undatedName(m.tries[i].Basename, sep)] > 1
+	}
 }
 
-func (m *model) calculateScore(try tryEntry) float64 {
-	score := 0.0
+// Separators name_separator may be set to; each is a single byte
+var nameSeparators = []string{"-", "_", "."}
 
-	// Bonus for date-prefixed directories
-	if strings.HasPrefix(try.Basename, "20") && len(try.Basename) > 10 {
-		if try.Basename[4] == '-' && try.Basename[7] == '-' && try.Basename[10] == '-' {
-			score += 2.0
+func isValidNameSeparator(sep string) bool {
+	for _, candidate := range nameSeparators {
+		if sep == candidate {
+			return true
 		}
 	}
+	return false
+}
 
-	// Search query matching
-	if m.searchTerm != "" {
-		textLower := strings.ToLower(try.Basename)
-		queryLower := strings.ToLower(m.searchTerm)
-		queryChars := []rune(queryLower)
+// nameSeparator returns the configured name_separator, "-" by default
+func nameSeparator(config *Config) string {
+	if config == nil || config.NameSeparator == "" {
+		return "-"
+	}
+	return config.NameSeparator
+}
 
-		lastPos := -1
-		queryIdx := 0
+// splitDatePrefix splits a YYYY-MM-DD<sep>name basename into its date and name
+// parts. A "-" after the date is always accepted, so experiments created
+// before name_separator changed still show their date.
+func splitDatePrefix(name, sep string) (string, string, bool) {
+	if len(name) < 11 || strings.Count(name[:10], "-") != 2 || name[4] != '-' || name[7] != '-' {
+		return "", name, false
+	}
+	if name[10:11] != sep && name[10] != '-' {
+		return "", name, false
+	}
+	return name[:10], name[11:], true
+}
+
+// undatedName returns the lowercased name without its date prefix, used to detect duplicates
+func undatedName(name, sep string) string {
+	_, rest, _ := splitDatePrefix(name, sep)
+	return strings.ToLower(rest)
+}
+
+// Match modes, cycled with ctrl+/ in the TUI
+const (
+	matchFuzzy = iota
+	matchSubstring
+	matchRegex
+)
+
+var matchModeNames = []string{"fuzzy", "substring", "regex"}
+
+func (m *model) filterTries() {
+	m.query, m.only = parseSearchTokens(m.searchTerm)
+	var dateWords string
+	m.query, dateWords, m.dates = parseDateTokens(m.query, time.Now())
+
+	// A URL that is already cloned offers the clone instead of a second copy
+	m.existingClone = nil
+	if isURL, cloneURL := isRepoURL(m.query); isURL && (m.config == nil || !m.config.ForceClone) {
+		if entry, ok := findClone(m.tries, cloneURL); ok {
+			m.existingClone = &entry
+		}
+	}
+
+	// Compile once per filter pass; an invalid pattern simply matches nothing
+	m.searchRegex, m.regexErr = nil, nil
+	if m.matchMode == matchRegex && m.query != "" {
+		m.searchRegex, m.regexErr = regexp.Compile(m.query)
+	}
+
+	if len(m.index.entries) != len(m.tries) {
+		m.index = buildIndex(m.tries)
+	}
+	fuzzy := m.matchMode == matchFuzzy && m.query != ""
+	sep := nameSeparator(m.config)
+	weights := scoringWeights(m.config)
+	var words [][]rune
+	var queryMask uint64
+	if fuzzy {
+		for _, word := range strings.Fields(strings.ToLower(m.query)) {
+			chars := []rune(word)
+			words = append(words, chars)
+			queryMask |= runeMask(chars)
+		}
+	}
+
+	// Typing more of a fuzzy query only ever narrows the results, so only the
+	// entries that matched the shorter query need scoring again
+	scope := fmt.Sprintf("%s/%t/%s", m.only, m.showBookmarks, dateWords)
+	candidates := m.index.candidates
+	if !fuzzy || m.index.query == "" || m.index.scope != scope || !strings.HasPrefix(m.query, m.index.query) {
+		candidates = make([]int, len(m.tries))
+		for i := range candidates {
+			candidates[i] = i
+		}
+	}
+
+	m.filteredTries = make([]tryEntry, 0, len(candidates))
+	var matches []int
+	for _, i := range candidates {
+		try := m.tries[i]
+		if m.showBookmarks && !m.isBookmarked(try) {
+			continue
+		}
+		if !m.since.IsZero() && !try.MTime.After(m.since) {
+			continue
+		}
+		if (m.only == "repo" && !try.IsRepo) || (m.only == "scratch" && try.IsRepo) {
+			continue
+		}
+		if m.dates != nil && (try.Date.IsZero() || !m.dates(try.Date)) {
+			continue
+		}
+		if m.query != "" && m.matchMode != matchFuzzy {
+			if !m.matchesLiteral(try.Basename) {
+				continue
+			}
+			// Only the date and time bonuses rank non-fuzzy matches
+			try.Score = datePrefixBonus(try, sep, weights) + recencyScore(try, weights)
+			m.filteredTries = append(m.filteredTries, try)
+			continue
+		}
+
+		entry := m.index.entries[i]
+		if entry.mask&queryMask != queryMask {
+			continue
+		}
+		score := calculateScore(try, entry.chars, words, sep, weights)
+		try.Score = score
+
+		if m.query == "" || score > 0 {
+			m.filteredTries = append(m.filteredTries, try)
+			matches = append(matches, i)
+		}
+	}
 
-		for pos, char := range textLower {
-			if queryIdx >= len(queryChars) {
+	m.index.candidates, m.index.query, m.index.scope = nil, "", ""
+	if fuzzy {
+		m.index.candidates, m.index.query, m.index.scope = matches, m.query, scope
+	}
+
+	m.sortTries()
+}
+
+// Search tokens that narrow the list by kind; "repo:redis" and "redis repo:" both work
+var kindTokens = []string{"repo:", "scratch:"}
+
+// parseSearchTokens splits kind tokens out of a search term, returning the
+// remaining query and the kind to keep ("repo", "scratch" or "" for all)
+func parseSearchTokens(term string) (query, only string) {
+	var words []string
+	for _, word := range strings.Split(term, " ") {
+		for _, token := range kindTokens {
+			if strings.HasPrefix(word, token) {
+				only = strings.TrimSuffix(token, ":")
+				word = word[len(token):]
 				break
 			}
-			if char != queryChars[queryIdx] {
-				continue
+		}
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " "), only
+}
+
+// dateMatcher reports whether a name's date fits the date tokens searched for
+type dateMatcher func(time.Time) bool
+
+// Search token prefix for date filters, like kindTokens: "date:jan"
+const dateTokenPrefix = "date:"
+
+// parseDateTokens splits date tokens out of a query: "date:" followed by a
+// month name ("jan", "march"), a month or a day ("2024-03", "2024-03-15"),
+// or today, yesterday and thisweek (since Monday). The prefix keeps plain
+// words like "may" matching names. A value that isn't a date yet, e.g. while
+// it's being typed, is left out without filtering. It returns the remaining
+// query, the tokens found and a matcher requiring every one of them, nil if
+// there were none.
+func parseDateTokens(query string, now time.Time) (string, string, dateMatcher) {
+	var words, tokens []string
+	var matchers []dateMatcher
+	for _, word := range strings.Split(query, " ") {
+		lower := strings.ToLower(word)
+		if strings.HasPrefix(lower, dateTokenPrefix) {
+			value := lower[len(dateTokenPrefix):]
+			if match, ok := dateToken(value, now); ok {
+				tokens = append(tokens, value)
+				matchers = append(matchers, match)
+			}
+			continue
+		}
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+	if len(matchers) == 0 {
+		return query, "", nil
+	}
+	return strings.Join(words, " "), strings.Join(tokens, " "), func(date time.Time) bool {
+		for _, match := range matchers {
+			if !match(date) {
+				return false
 			}
+		}
+		return true
+	}
+}
 
-			// Base point + word boundary bonus
-			score += 1.0
-			if pos == 0 || (pos > 0 && !isAlphaNum(rune(textLower[pos-1]))) {
-				score += 1.0
+// dateToken returns the matcher for word if it is a date token, see parseDateTokens
+func dateToken(word string, now time.Time) (dateMatcher, bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	within := func(from, to time.Time) (dateMatcher, bool) {
+		return func(date time.Time) bool {
+			return !date.Before(from) && date.Before(to)
+		}, true
+	}
+
+	switch word {
+	case "today":
+		return within(today, today.AddDate(0, 0, 1))
+	case "yesterday":
+		return within(today.AddDate(0, 0, -1), today)
+	case "thisweek":
+		monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+		return within(monday, monday.AddDate(0, 0, 7))
+	}
+	for month := time.January; month <= time.December; month++ {
+		name := strings.ToLower(month.String())
+		if word == name || word == name[:3] {
+			return func(date time.Time) bool {
+				return date.Month() == month
+			}, true
+		}
+	}
+	if day, err := time.ParseInLocation("2006-01-02", word, now.Location()); err == nil {
+		return within(day, day.AddDate(0, 0, 1))
+	}
+	if first, err := time.ParseInLocation("2006-01", word, now.Location()); err == nil {
+		return within(first, first.AddDate(0, 1, 0))
+	}
+	return nil, false
+}
+
+// Sort modes; score blends fuzzy match and recency, name is alphabetical
+// ignoring the date prefix, and the others order purely by timestamp
+var sortModes = []string{"score", "name", "created", "accessed"}
+
+func isValidSortMode(mode string) bool {
+	for _, candidate := range sortModes {
+		if mode == candidate {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *model) sortTries() {
+	tries := m.filteredTries
+	// Ties fall back to the most recently accessed and then the name, so equal
+	// entries don't swap places between redraws
+	tieBreak := func(i, j int) bool {
+		if !tries[i].MTime.Equal(tries[j].MTime) {
+			return tries[i].MTime.After(tries[j].MTime)
+		}
+		return tries[i].Path < tries[j].Path
+	}
+	switch m.sortMode {
+	case "created":
+		sort.Slice(tries, func(i, j int) bool {
+			if !tries[i].CTime.Equal(tries[j].CTime) {
+				return tries[i].CTime.After(tries[j].CTime)
+			}
+			return tieBreak(i, j)
+		})
+	case "name":
+		sep := nameSeparator(m.config)
+		sort.Slice(tries, func(i, j int) bool {
+			a := strings.ToLower(undatedName(tries[i].Basename, sep))
+			b := strings.ToLower(undatedName(tries[j].Basename, sep))
+			if a != b {
+				return a < b
+			}
+			return tieBreak(i, j)
+		})
+	case "accessed":
+		sort.Slice(tries, tieBreak)
+	default:
+		// Sort by score descending; with --grep, by matches first
+		sort.Slice(tries, func(i, j int) bool {
+			if tries[i].Hits != tries[j].Hits {
+				return tries[i].Hits > tries[j].Hits
+			}
+			if tries[i].Score != tries[j].Score {
+				return tries[i].Score > tries[j].Score
+			}
+			return tieBreak(i, j)
+		})
+	}
+	if m.config != nil && len(m.config.Pinned) > 0 {
+		// Pinned entries go first whatever the order, each group keeping it
+		sort.SliceStable(tries, func(i, j int) bool {
+			return m.isPinned(tries[i]) && !m.isPinned(tries[j])
+		})
+	}
+	m.scorePrecision = scorePrecision(tries)
+}
+
+// scorePrecision picks how many decimals the score column needs (1 to 3) so
+// that neighbouring entries with different scores also display differently
+func scorePrecision(tries []tryEntry) int {
+	const maxPrecision = 3
+	for precision := 1; precision < maxPrecision; precision++ {
+		distinct := true
+		for i := 1; i < len(tries) && distinct; i++ {
+			a, b := tries[i-1].Score, tries[i].Score
+			if a != b && fmt.Sprintf("%.*f", precision, a) == fmt.Sprintf("%.*f", precision, b) {
+				distinct = false
 			}
+		}
+		if distinct {
+			return precision
+		}
+	}
+	return maxPrecision
+}
+
+// calculateScore ranks an entry against the lowercased query words. Each
+// word must match on its own, in any order, and their points add up.
+// textChars is the lowercased basename; runes make positions and gaps count
+// characters, not bytes.
+func calculateScore(try tryEntry, textChars []rune, words [][]rune, sep string, weights scoreWeights) float64 {
+	score := datePrefixBonus(try, sep, weights)
 
-			// Proximity bonus
-			if lastPos >= 0 {
-				gap := pos - lastPos - 1
-				score += 1.0 / math.Sqrt(float64(gap+1))
+	// Search query matching
+	if len(words) > 0 {
+		matched, end := 0, 0
+
+		for _, queryChars := range words {
+			points, lastPos, ok := fuzzyPoints(textChars, queryChars)
+			// Return 0 if any word didn't match
+			if !ok {
+				return 0.0
+			}
+			score += points
+			matched += len(queryChars)
+			if lastPos+1 > end {
+				end = lastPos + 1
 			}
+		}
+
+		// Density bonus; overlapping words can't make it a bonus above 1
+		if end > 0 {
+			score *= math.Min(1, float64(matched)/float64(end))
+		}
+
+		// Length penalty
+		score *= weights.lengthPenalty / (float64(len(textChars)) + weights.lengthPenalty)
+	}
+
+	return score + recencyScore(try, weights)
+}
 
-			lastPos = pos
-			queryIdx++
+// fuzzyPoints matches queryChars as a subsequence of textChars, returning the
+// match points and the position of the last matched character
+func fuzzyPoints(textChars, queryChars []rune) (float64, int, bool) {
+	score := 0.0
+	lastPos := -1
+	queryIdx := 0
+
+	for pos, char := range textChars {
+		if queryIdx >= len(queryChars) {
+			break
+		}
+		if char != queryChars[queryIdx] {
+			continue
 		}
 
-		// Return 0 if not all query chars matched
-		if queryIdx < len(queryChars) {
-			return 0.0
+		// Base point + word boundary bonus
+		score += 1.0
+		if pos == 0 || !isAlphaNum(textChars[pos-1]) {
+			score += 1.0
 		}
 
-		// Density bonus
+		// Proximity bonus
 		if lastPos >= 0 {
-			score *= float64(len(queryChars)) / float64(lastPos+1)
+			gap := pos - lastPos - 1
+			score += 1.0 / math.Sqrt(float64(gap+1))
 		}
 
-		// Length penalty
-		score *= 10.0 / (float64(len(try.Basename)) + 10.0)
+		lastPos = pos
+		queryIdx++
+	}
+
+	return score, lastPos, queryIdx == len(queryChars)
+}
+
+// scoreWeights are the constants the score is built from
+type scoreWeights struct {
+	datePrefix    float64
+	created       float64
+	accessed      float64
+	lengthPenalty float64
+}
+
+var defaultScoreWeights = scoreWeights{datePrefix: 2.0, created: 2.0, accessed: 3.0, lengthPenalty: 10.0}
+
+// scoringWeights applies the config's scoring overrides to the defaults
+func scoringWeights(config *Config) scoreWeights {
+	weights := defaultScoreWeights
+	if config == nil || config.Scoring == nil {
+		return weights
+	}
+	for _, override := range []struct {
+		value  *float64
+		weight *float64
+	}{
+		{config.Scoring.DatePrefixBonus, &weights.datePrefix},
+		{config.Scoring.CreatedWeight, &weights.created},
+		{config.Scoring.AccessedWeight, &weights.accessed},
+		{config.Scoring.LengthPenalty, &weights.lengthPenalty},
+	} {
+		if override.value != nil {
+			*override.weight = *override.value
+		}
 	}
+	return weights
+}
+
+// datePrefixBonus rewards date-prefixed directories
+func datePrefixBonus(try tryEntry, sep string, weights scoreWeights) float64 {
+	if _, _, ok := splitDatePrefix(try.Basename, sep); ok && strings.HasPrefix(try.Basename, "20") {
+		return weights.datePrefix
+	}
+	return 0.0
+}
 
-	// Time-based scoring
+// recencyScore is the time-based part of the score
+func recencyScore(try tryEntry, weights scoreWeights) float64 {
 	now := time.Now()
 
 	// Creation time bonus
 	daysOld := now.Sub(try.CTime).Hours() / 24
-	score += 2.0 / math.Sqrt(daysOld+1)
+	score := weights.created / math.Sqrt(daysOld+1)
 
 	// Access time bonus
 	hoursAccess := now.Sub(try.MTime).Hours()
-	score += 3.0 / math.Sqrt(hoursAccess+1)
+	score += weights.accessed / math.Sqrt(hoursAccess+1)
 
 	return score
 }
 
+// matchesLiteral reports whether name matches the search term in substring or regex mode
+func (m *model) matchesLiteral(name string) bool {
+	start, _ := m.literalMatch(name)
+	return start >= 0
+}
+
+// regexProblem says briefly what is wrong with a pattern that didn't compile,
+// e.g. "missing closing )"
+func regexProblem(err error) string {
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		return string(syntaxErr.Code)
+	}
+	return err.Error()
+}
+
+// literalMatch returns the span of the substring or regex match in text, or -1, -1
+func (m *model) literalMatch(text string) (int, int) {
+	switch m.matchMode {
+	case matchSubstring:
+		return indexFold(text, m.query)
+	case matchRegex:
+		if m.searchRegex == nil {
+			return -1, -1
+		}
+		if loc := m.searchRegex.FindStringIndex(text); loc != nil {
+			return loc[0], loc[1]
+		}
+	}
+	return -1, -1
+}
+
+// indexFold finds query in text ignoring case and returns the byte span it
+// covers in text, or -1, -1. Unlike searching strings.ToLower(text) the span
+// stays right when case folding changes a character's length in bytes.
+func indexFold(text, query string) (int, int) {
+	if query == "" {
+		return 0, 0
+	}
+	for start := range text {
+		end := start
+		matched := true
+		for _, want := range query {
+			if end >= len(text) {
+				matched = false
+				break
+			}
+			got, size := utf8.DecodeRuneInString(text[end:])
+			if got != want && unicode.ToLower(got) != unicode.ToLower(want) && unicode.ToUpper(got) != unicode.ToUpper(want) {
+				matched = false
+				break
+			}
+			end += size
+		}
+		if matched {
+			return start, end
+		}
+	}
+	return -1, -1
+}
+
 func isAlphaNum(r rune) bool {
 	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
 }
@@ -646,7 +2436,7 @@ func isAlphaNum(r rune) bool {
 func isValidSearchInput(input string) bool {
 	for _, char := range input {
 		if !((char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') ||
-			(char >= '0' && char <= '9') || 
+			(char >= '0' && char <= '9') ||
 			char == '-' || char == '_' || char == '.' || char == ' ' ||
 			char == ':' || char == '/' || char == '@') {
 			return false
@@ -655,37 +2445,104 @@ func isValidSearchInput(input string) bool {
 	return len(input) > 0
 }
 
-// Pre-compiled GitHub URL patterns
-var githubPatterns = []struct {
+// isPrintableInput allows the wider character set regex patterns need
+func isPrintableInput(input string) bool {
+	for _, char := range input {
+		if !unicode.IsPrint(char) {
+			return false
+		}
+	}
+	return len(input) > 0
+}
+
+// repoProvider is a git host whose URLs the search box and --clone accept
+type repoProvider struct {
+	host      string
+	shorthand string // Typed as <shorthand>:user/repo
+	owner     string // Pattern for the owner part of the path
+	webPath   string // What a link to a branch, directory or file adds after owner/repo
+}
+
+// Segments start with a word character, which keeps GitLab's "/-/" out of group names
+const (
+	ownerSegment = `[\w][\w.-]*`
+	repoSegment  = `[\w][\w.-]*?`
+)
+
+var repoProviders = []repoProvider{
+	{host: "github.com", shorthand: "gh", owner: `[\w-]+`, webPath: `/(?:tree|blob)/[^?#]+`},
+	// GitLab groups can nest: gitlab.com/group/subgroup/repo
+	{host: "gitlab.com", shorthand: "gl", owner: ownerSegment + `(?:/` + ownerSegment + `)*`, webPath: `/-/(?:tree|blob)/[^?#]+`},
+	{host: "bitbucket.org", shorthand: "bb", owner: `[\w-]+`, webPath: `/src/[^?#]+`},
+	{host: "codeberg.org", shorthand: "cb", owner: `[\w-]+`, webPath: `/src/[^?#]+`},
+}
+
+// repoPatterns are the pre-compiled URL patterns of every provider. Links into
+// a repository, e.g. /tree/main/docs or /blob/main/main.go#L10, clone the
+// whole repository.
+var repoPatterns = compileRepoPatterns(repoProviders)
+
+// repoPattern is one URL form; format expands its owner and repo into the clone URL
+type repoPattern struct {
 	regex  *regexp.Regexp
 	format string
-}{
-	{regexp.MustCompile(`^https?://github\.com/([\w-]+)/([\w\.-]+?)(?:\.git)?/?$`), "https://github.com/$1/$2.git"},
-	{regexp.MustCompile(`^github\.com/([\w-]+)/([\w\.-]+?)(?:\.git)?/?$`), "https://github.com/$1/$2.git"},
-	{regexp.MustCompile(`^git@github\.com:([\w-]+)/([\w\.-]+?)(?:\.git)?$`), "https://github.com/$1/$2.git"},
-	{regexp.MustCompile(`^gh:([\w-]+)/([\w\.-]+?)$`), "https://github.com/$1/$2.git"},
 }
 
-// isGitHubURL checks if the text is a GitHub URL and returns normalized clone URL
-func isGitHubURL(text string) (bool, string) {
+func compileRepoPatterns(providers []repoProvider) []repoPattern {
+	var patterns []repoPattern
+	add := func(pattern, format string) {
+		patterns = append(patterns, repoPattern{regexp.MustCompile(pattern), format})
+	}
+	for _, p := range providers {
+		host := regexp.QuoteMeta(p.host)
+		path := `(` + p.owner + `)/(` + repoSegment + `)`
+		web := `(?:` + p.webPath + `)?/?(?:[?#].*)?`
+		format := "https://" + p.host + "/$1/$2.git"
+		add(`^https?://`+host+`/`+path+`(?:\.git)?`+web+`$`, format)
+		add(`^`+host+`/`+path+`(?:\.git)?`+web+`$`, format)
+		add(`^git@`+host+`:`+path+`(?:\.git)?$`, format)
+		add(`^`+p.shorthand+`:`+path+web+`$`, format)
+	}
+	return patterns
+}
+
+// isRepoURL checks if the text is a repository URL or shorthand of a known
+// provider and returns the normalized clone URL
+func isRepoURL(text string) (bool, string) {
 	text = strings.TrimSpace(text)
-	
-	for _, p := range githubPatterns {
-		if matches := p.regex.FindStringSubmatch(text); matches != nil {
-			user := matches[1]
-			repo := matches[2]
-			return true, fmt.Sprintf("https://github.com/%s/%s.git", user, repo)
+
+	for _, p := range repoPatterns {
+		if matches := p.regex.FindStringSubmatchIndex(text); matches != nil {
+			return true, string(p.regex.ExpandString(nil, p.format, text, matches))
 		}
 	}
-	
+
 	return false, ""
 }
 
-// extractRepoName extracts the repository name from a GitHub URL
+// cloneTransportURL rewrites a clone URL from isRepoURL for the configured
+// clone_protocol: with ssh, https://host/owner/repo.git becomes
+// git@host:owner/repo.git
+func cloneTransportURL(cloneURL string, config *Config) string {
+	if config == nil || config.CloneProtocol != "ssh" {
+		return cloneURL
+	}
+	rest, ok := strings.CutPrefix(cloneURL, "https://")
+	if !ok {
+		return cloneURL
+	}
+	host, path, ok := strings.Cut(rest, "/")
+	if !ok {
+		return cloneURL
+	}
+	return "git@" + host + ":" + path
+}
+
+// extractRepoName extracts the repository name from a clone URL
 func extractRepoName(url string) string {
 	// Remove .git suffix
 	url = strings.TrimSuffix(url, ".git")
-	
+
 	// Extract repo name from URL
 	parts := strings.Split(url, "/")
 	if len(parts) >= 2 {
@@ -700,609 +2557,3850 @@ func extractRepoName(url string) string {
 		}
 		return repoName
 	}
-	
+
 	return "repo"
 }
 
-// cloneRepository clones a git repository to the specified path with timeout
-func cloneRepository(url, targetPath string) error {
-	// Check if git is available
-	if _, err := exec.LookPath("git"); err != nil {
-		return fmt.Errorf("git is not installed")
+// gitDir returns the directory holding dir's git config, following the
+// "gitdir:" pointer that worktrees and submodules use in place of .git/
+func gitDir(dir string) (string, error) {
+	dotGit := filepath.Join(dir, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
 	}
-	
-	// Create the target directory
-	if err := os.MkdirAll(targetPath, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %v", err)
+	if info.IsDir() {
+		return dotGit, nil
 	}
-	
-	// Clone the repository with timeout
-	cmd := exec.Command("git", "clone", "--depth", "1", url, targetPath)
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	
-	// Set a 2-minute timeout for clone operation
-	done := make(chan error, 1)
-	go func() {
-		done <- cmd.Run()
-	}()
-	
-	select {
-	case err := <-done:
-		if err != nil {
-			// If clone failed, remove the directory
-			os.RemoveAll(targetPath)
-			return fmt.Errorf("failed to clone repository: %v", err)
+
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	target := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(string(data)), "gitdir:"))
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	// Worktrees share the main repository's config
+	if common, err := os.ReadFile(filepath.Join(target, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(target, commonDir)
 		}
-		return nil
-	case <-time.After(2 * time.Minute):
-		cmd.Process.Kill()
-		os.RemoveAll(targetPath)
-		return fmt.Errorf("clone operation timed out after 2 minutes")
+		return commonDir, nil
 	}
+	return target, nil
 }
 
-// performClone handles the common clone operation logic
-func performClone(cloneURL, basePath string) (string, error) {
-	// Extract repo name and create dated folder name
-	repoName := extractRepoName(cloneURL)
-	datePrefix := time.Now().Format("2006-01-02")
-	dirName := fmt.Sprintf("%s-%s", datePrefix, repoName)
-	fullPath := filepath.Join(basePath, dirName)
-	
-	// Check if directory already exists
-	if _, err := os.Stat(fullPath); err == nil {
-		// Add a number suffix if it exists
-		for i := 2; ; i++ {
-			testPath := fmt.Sprintf("%s-%d", fullPath, i)
-			if _, err := os.Stat(testPath); os.IsNotExist(err) {
-				fullPath = testPath
-				dirName = fmt.Sprintf("%s-%d", dirName, i)
-				break
-			}
-		}
+// readOriginURL returns the url of the "origin" remote from dir's git config
+func readOriginURL(dir string) (string, error) {
+	gd, err := gitDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("not a git repository")
 	}
-	
-	// Clone the repository
-	fmt.Printf("📦 Cloning %s into %s...\n", cloneURL, dirName)
-	if err := cloneRepository(cloneURL, fullPath); err != nil {
+	data, err := os.ReadFile(filepath.Join(gd, "config"))
+	if err != nil {
 		return "", err
 	}
-	
-	return fullPath, nil
+
+	inOrigin := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+	return "", fmt.Errorf("no origin remote")
 }
 
-func (m model) Init() tea.Cmd {
-	return tea.EnterAltScreen
+// findClone returns the checkout among entries whose origin is the
+// repository at cloneURL, however either URL is spelled
+func findClone(entries []tryEntry, cloneURL string) (tryEntry, bool) {
+	want, ok := webURLFromRemote(cloneURL)
+	if !ok {
+		return tryEntry{}, false
+	}
+	for _, entry := range entries {
+		if !entry.IsRepo {
+			continue
+		}
+		remote, err := readOriginURL(entry.Path)
+		if err != nil {
+			continue
+		}
+		if have, ok := webURLFromRemote(remote); ok && strings.EqualFold(have, want) {
+			return entry, true
+		}
+	}
+	return tryEntry{}, false
 }
 
-func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
+// webURLFromRemote turns a git remote (https, ssh:// or scp-style
+// git@host:owner/repo) into the https page for the repository
+func webURLFromRemote(remote string) (string, bool) {
+	remote = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(remote), "/"), ".git")
+
+	var host, path string
+	if i := strings.Index(remote, "://"); i >= 0 {
+		rest := remote[i+3:]
+		host, path, _ = strings.Cut(rest, "/")
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		// ssh ports aren't web ports
+		if strings.HasPrefix(remote, "ssh://") {
+			host, _, _ = strings.Cut(host, ":")
+		}
+	} else if at := strings.Index(remote, "@"); at >= 0 {
+		// scp-style: git@github.com:owner/repo
+		host, path, _ = strings.Cut(remote[at+1:], ":")
+	} else {
+		return "", false
+	}
 
-	case tea.KeyMsg:
-		// Handle input mode for new directory name
-		if m.inputMode {
+	if host == "" || path == "" {
+		return "", false
+	}
+	return "https://" + host + "/" + strings.TrimPrefix(path, "/"), true
+}
+
+// openURL opens url with the platform's default handler without waiting
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// clipboardCommands are the clipboard readers tried on each platform, in order
+var clipboardCommands = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}},
+	"linux":   {{"wl-paste", "--no-newline"}, {"xclip", "-o", "-selection", "clipboard"}, {"xsel", "--clipboard", "--output"}},
+}
+
+// readClipboard returns the clipboard contents using the first available
+// platform tool
+func readClipboard() (string, error) {
+	candidates, ok := clipboardCommands[runtime.GOOS]
+	if !ok {
+		// Other unixes usually have the X11 tools
+		candidates = clipboardCommands["linux"]
+	}
+	var tried []string
+	for _, args := range candidates {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			tried = append(tried, args[0])
+			continue
+		}
+		out, err := exec.Command(args[0], args[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("%s failed: %w", args[0], err)
+		}
+		return string(out), nil
+	}
+	return "", fmt.Errorf("no clipboard tool found (tried %s)", strings.Join(tried, ", "))
+}
+
+// clipboardSearch turns clipboard contents into a search: its first line,
+// with characters the search box doesn't accept replaced by "-"
+func clipboardSearch(text string) string {
+	line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(text), "\n", 2)[0])
+	if isValidSearchInput(line) {
+		return line
+	}
+	cleaned := strings.Map(func(r rune) rune {
+		if isValidSearchInput(string(r)) {
+			return r
+		}
+		return '-'
+	}, line)
+	return strings.Trim(cleaned, "-")
+}
+
+// repoWebURL finds the browsable upstream page of an experiment
+func repoWebURL(entry tryEntry) (string, error) {
+	if !entry.IsRepo {
+		return "", fmt.Errorf("%s is not a git repository", entry.Basename)
+	}
+	remote, err := readOriginURL(entry.Path)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", entry.Basename, err)
+	}
+	url, ok := webURLFromRemote(remote)
+	if !ok {
+		return "", fmt.Errorf("%s: can't make a web address from %s", entry.Basename, remote)
+	}
+	return url, nil
+}
+
+// cloneRepository clones a git repository to the specified path with timeout
+func cloneRepository(url, targetPath string, config *Config) error {
+	// Check if git is available
+	if _, err := exec.LookPath("git"); err != nil {
+		return fmt.Errorf("git is not installed")
+	}
+
+	// Create the target directory
+	if err := os.MkdirAll(targetPath, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+
+	// Clone the repository with timeout
+	args := []string{"clone"}
+	depth := cloneDepth(config)
+	if depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(depth))
+	}
+	if config != nil && config.CloneSubmodules {
+		args = append(args, "--recurse-submodules")
+		if depth > 0 {
+			args = append(args, "--shallow-submodules")
+		}
+	}
+	if config != nil && config.CloneBranch != "" {
+		args = append(args, "--branch", config.CloneBranch, "--single-branch")
+	}
+	cmd := exec.Command("git", append(args, url, targetPath)...)
+	// Keep what git said, so the reason for a failure outlives the cleanup
+	var gitErr strings.Builder
+	cmd.Stderr = io.MultiWriter(os.Stderr, &gitErr)
+	// git output is for humans; stdout is reserved for machine output like --select-only paths
+	cmd.Stdout = os.Stderr
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Run()
+	}()
+
+	// A nil channel never fires, for clones without a timeout
+	timeout := cloneTimeout(config)
+	var expired <-chan time.Time
+	if timeout > 0 {
+		expired = time.After(timeout)
+	}
+
+	select {
+	case err := <-done:
+		if err != nil {
+			// If clone failed, remove the directory
+			os.RemoveAll(targetPath)
+			if reason := lastLine(gitErr.String()); reason != "" {
+				return fmt.Errorf("failed to clone repository: %s", reason)
+			}
+			return fmt.Errorf("failed to clone repository: %v", err)
+		}
+		setCloneIdentity(targetPath, config)
+		return nil
+	case <-expired:
+		cmd.Process.Kill()
+		os.RemoveAll(targetPath)
+		return fmt.Errorf("clone operation timed out after %v (see clone_timeout_seconds)", timeout)
+	}
+}
+
+// lastLine returns the last non-blank line of s, trimmed
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+// cloneDepth returns how many commits to clone, 0 meaning the full history.
+// Clones are shallow unless clone_depth says otherwise.
+func cloneDepth(config *Config) int {
+	if config == nil || config.CloneDepth == nil {
+		return 1
+	}
+	return *config.CloneDepth
+}
+
+// defaultCloneTimeout applies when clone_timeout_seconds is 0 or unset
+const defaultCloneTimeout = 2 * time.Minute
+
+// cloneTimeout returns how long a clone may take, or 0 for no limit
+func cloneTimeout(config *Config) time.Duration {
+	if config == nil || config.CloneTimeoutSeconds == 0 {
+		return defaultCloneTimeout
+	}
+	if config.CloneTimeoutSeconds < 0 {
+		return 0
+	}
+	return time.Duration(config.CloneTimeoutSeconds) * time.Second
+}
+
+// Device names Windows reserves, with or without an extension
+var reservedNames = map[string]bool{
+	"con": true, "prn": true, "aux": true, "nul": true,
+	"com1": true, "com2": true, "com3": true, "com4": true, "com5": true, "com6": true, "com7": true, "com8": true, "com9": true,
+	"lpt1": true, "lpt2": true, "lpt3": true, "lpt4": true, "lpt5": true, "lpt6": true, "lpt7": true, "lpt8": true, "lpt9": true,
+}
+
+// validateExperimentName checks that name can be created as a directory on
+// any platform, so a bad name is caught while it can still be edited rather
+// than by os.MkdirAll after the selector has quit
+func validateExperimentName(name string) error {
+	if name == "" || name == "." || name == ".." {
+		return fmt.Errorf("%q isn't a usable name", name)
+	}
+	if len(name) > 255 {
+		return fmt.Errorf("the name is longer than 255 bytes")
+	}
+	for _, char := range name {
+		if char < ' ' || strings.ContainsRune(`<>:"/\|?*`, char) {
+			return fmt.Errorf("names can't contain %q", char)
+		}
+	}
+	if strings.HasSuffix(name, ".") || strings.HasSuffix(name, " ") {
+		return fmt.Errorf("names can't end with %q", name[len(name)-1:])
+	}
+	if stem, _, _ := strings.Cut(name, "."); reservedNames[strings.ToLower(stem)] {
+		return fmt.Errorf("%q is a reserved device name on Windows", stem)
+	}
+	return nil
+}
+
+// setCloneIdentity gives a fresh clone the configured commit identity, so
+// experiment commits needn't carry the global one. Failures only warn: the
+// clone itself is fine.
+func setCloneIdentity(repoPath string, config *Config) {
+	if config == nil {
+		return
+	}
+	for key, value := range map[string]string{"user.name": config.CloneGitUser, "user.email": config.CloneGitEmail} {
+		if value == "" {
+			continue
+		}
+		cmd := exec.Command("git", "config", key, value)
+		cmd.Dir = repoPath
+		if out, err := cmd.CombinedOutput(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: couldn't set %s: %v %s\n", key, err, strings.TrimSpace(string(out)))
+		}
+	}
+}
+
+// datedName builds today's date-prefixed directory name, e.g. 2025-08-17-redis-test,
+// with name_separator in place of the dashes after the date
+func datedName(name string, config *Config) string {
+	datePrefix := time.Now().Format("2006-01-02")
+	sep := nameSeparator(config)
+	return datePrefix + sep + strings.ReplaceAll(name, " ", sep)
+}
+
+// nextFreeName returns dirName, or dirName with the first free -N suffix if it already exists in basePath
+func nextFreeName(basePath, dirName string) string {
+	if _, err := os.Stat(filepath.Join(basePath, dirName)); os.IsNotExist(err) {
+		return dirName
+	}
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", dirName, i)
+		if _, err := os.Stat(filepath.Join(basePath, candidate)); os.IsNotExist(err) {
+			return candidate
+		}
+	}
+}
+
+// promptCloneName asks on stderr for an alternative directory name when a clone target already exists
+func promptCloneName(basePath, dirName, suggested string) string {
+	reader := bufio.NewReader(os.Stdin)
+	fmt.Fprintf(os.Stderr, "%s\n", warningStyle.Render(fmt.Sprintf("%s %s already exists", icon("warning"), dirName)))
+	for {
+		fmt.Fprintf(os.Stderr, "%s [%s]: ", promptStyle.Render("Name for the clone"), dimStyle.Render(suggested))
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if err != nil && input == "" {
+			// No usable input (e.g. EOF), fall back to the suffixed name
+			fmt.Fprintln(os.Stderr)
+			return suggested
+		}
+		if input == "" {
+			return suggested
+		}
+		if input == "." || input == ".." || strings.ContainsAny(input, `/\`) {
+			fmt.Fprintln(os.Stderr, "Name must be a single directory name")
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(basePath, input)); err == nil {
+			fmt.Fprintf(os.Stderr, "%s already exists, pick another name\n", input)
+			continue
+		}
+		return input
+	}
+}
+
+// isInteractive reports whether we can prompt the user outside the TUI
+func isInteractive() bool {
+	return isatty(os.Stdin.Fd()) && isatty(os.Stderr.Fd())
+}
+
+// performClone handles the common clone operation logic.
+// On a name collision an interactive user is asked for an alternative name,
+// otherwise a numeric suffix is appended.
+func performClone(cloneURL, basePath string, config *Config, interactive bool) (string, error) {
+	// Extract repo name and create dated folder name
+	repoName := extractRepoName(cloneURL)
+	dirName := datedName(repoName, config)
+
+	// Check if directory already exists
+	if suggested := nextFreeName(basePath, dirName); suggested != dirName {
+		if interactive {
+			dirName = promptCloneName(basePath, dirName, suggested)
+		} else {
+			dirName = suggested
+		}
+	}
+	fullPath := filepath.Join(basePath, dirName)
+
+	// Clone the repository
+	cloneURL = cloneTransportURL(cloneURL, config)
+	fmt.Fprintf(os.Stderr, "%s Cloning %s into %s...\n", icon("clone"), cloneURL, dirName)
+	if err := cloneRepository(cloneURL, fullPath, config); err != nil {
+		return "", err
+	}
+
+	return fullPath, nil
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.EnterAltScreen
+}
+
+// configRef returns how config settings like aliases and bookmarks refer to
+// entry: its name for experiments in the base path, its full path otherwise
+func (m model) configRef(entry tryEntry) string {
+	if filepath.Dir(entry.Path) == m.basePath {
+		return entry.Basename
+	}
+	return entry.Path
+}
+
+// isBookmarked reports whether entry is in the bookmarks
+func (m model) isBookmarked(entry tryEntry) bool {
+	return m.config != nil && m.listsRef(m.config.Bookmarks, entry)
+}
+
+// isPinned reports whether entry is pinned to the top of the list
+func (m model) isPinned(entry tryEntry) bool {
+	return m.config != nil && m.listsRef(m.config.Pinned, entry)
+}
+
+// listsRef reports whether refs, a config list like bookmarks, has entry
+func (m model) listsRef(refs []string, entry tryEntry) bool {
+	ref := m.configRef(entry)
+	for _, r := range refs {
+		if r == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// toggleBookmark adds entry to the bookmarks in the config file, or removes
+// it if it's already there. It returns the status message to show.
+func (m *model) toggleBookmark(entry tryEntry) string {
+	added, err := m.toggleRef(func(c *Config) *[]string { return &c.Bookmarks }, entry)
+	if err != nil {
+		return fmt.Sprintf("Couldn't save bookmark: %v", err)
+	}
+	if added {
+		return "Bookmarked " + entry.Basename
+	}
+	return "Removed bookmark for " + entry.Basename
+}
+
+// togglePin pins entry in the config file, or unpins it if it's already
+// pinned. It returns the status message to show.
+func (m *model) togglePin(entry tryEntry) string {
+	added, err := m.toggleRef(func(c *Config) *[]string { return &c.Pinned }, entry)
+	if err != nil {
+		return fmt.Sprintf("Couldn't save pin: %v", err)
+	}
+	if added {
+		return "Pinned " + entry.Basename
+	}
+	return "Unpinned " + entry.Basename
+}
+
+// toggleRef adds entry to the config list picked by field, or removes it if
+// it's already there, and reports whether it was added
+func (m *model) toggleRef(field func(*Config) *[]string, entry tryEntry) (bool, error) {
+	ref := m.configRef(entry)
+	added := false
+	config, err := updateConfig(func(c *Config) {
+		list := field(c)
+		var kept []string
+		for _, r := range *list {
+			if r != ref {
+				kept = append(kept, r)
+			}
+		}
+		if len(kept) == len(*list) {
+			kept = append(kept, ref)
+			added = true
+		}
+		*list = kept
+	})
+	if err != nil {
+		return false, err
+	}
+	if m.config != nil {
+		*field(m.config) = *field(config)
+	}
+	return added, nil
+}
+
+// aliasOf returns the alias already pointing at entry, if any
+func (m model) aliasOf(entry tryEntry) string {
+	if m.config == nil {
+		return ""
+	}
+	target := m.configRef(entry)
+	for alias, t := range m.config.Aliases {
+		if t == target {
+			return alias
+		}
+	}
+	return ""
+}
+
+// saveAlias points alias at entry in the config file, replacing any alias it
+// already had; an empty alias just removes the old one. It returns the status
+// message to show.
+func (m *model) saveAlias(entry tryEntry, alias string) string {
+	target := m.configRef(entry)
+	config, err := updateConfig(func(c *Config) {
+		for name, t := range c.Aliases {
+			if t == target {
+				delete(c.Aliases, name)
+			}
+		}
+		if alias != "" {
+			if c.Aliases == nil {
+				c.Aliases = make(map[string]string)
+			}
+			c.Aliases[alias] = target
+		}
+	})
+	if err != nil {
+		return fmt.Sprintf("Couldn't save alias: %v", err)
+	}
+	if m.config != nil {
+		m.config.Aliases = config.Aliases
+	}
+	if alias == "" {
+		return "Removed alias for " + entry.Basename
+	}
+	return fmt.Sprintf("try %s now opens %s", alias, entry.Basename)
+}
+
+// startRename opens the rename prompt for the highlighted entry, filled in
+// with its current name
+func (m *model) startRename() {
+	if m.cursor < len(m.filteredTries) && m.filteredTries[m.cursor].Trashed {
+		m.statusMsg = "Restore it first; Enter restores and opens it"
+	} else if m.cursor < len(m.filteredTries) {
+		entry := m.filteredTries[m.cursor]
+		m.renameTarget = &entry
+		m.renameName = entry.Basename
+	}
+}
+
+// renameEntry renames entry to name in the same root, refusing to replace
+// anything already there, and points its aliases and bookmarks at the new
+// name. It returns the status message to show and whether the rename is done.
+func (m *model) renameEntry(entry tryEntry, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == entry.Basename {
+		return "", true
+	}
+	if err := validateExperimentName(name); err != nil {
+		return "Can't rename it: " + err.Error(), false
+	}
+	newPath := filepath.Join(filepath.Dir(entry.Path), name)
+	if existing, err := os.Lstat(newPath); err == nil {
+		// A case-only rename on a case-insensitive filesystem finds the entry itself
+		if current, err := os.Lstat(entry.Path); err != nil || !os.SameFile(existing, current) {
+			return name + " already exists", false
+		}
+	}
+	if err := os.Rename(entry.Path, newPath); err != nil {
+		return fmt.Sprintf("Couldn't rename %s: %v", entry.Basename, err), false
+	}
+	m.actions = append(m.actions, fmt.Sprintf("renamed %s to %s", entry.Basename, name))
+
+	renamed := entry
+	renamed.Path, renamed.Basename = newPath, name
+	msg := "Renamed " + entry.Basename + " to " + name
+	if err := m.moveRefs(entry, renamed); err != nil {
+		msg += fmt.Sprintf(" (couldn't update its aliases and bookmarks: %v)", err)
+	}
+
+	m.loadTries()
+	m.filterTries()
+	for i, try := range m.filteredTries {
+		if try.Path == newPath {
+			m.cursor = i
+			break
+		}
+	}
+	m.adjustScroll()
+	return msg, true
+}
+
+// moveRefs points the aliases and bookmarks that refer to from at to instead.
+// The config file is only written if any did.
+func (m *model) moveRefs(from, to tryEntry) error {
+	if m.config == nil || (m.aliasOf(from) == "" && !m.isBookmarked(from)) {
+		return nil
+	}
+	oldRef, newRef := m.configRef(from), m.configRef(to)
+	config, err := updateConfig(func(c *Config) {
+		for alias, target := range c.Aliases {
+			if target == oldRef {
+				c.Aliases[alias] = newRef
+			}
+		}
+		for i, bookmark := range c.Bookmarks {
+			if bookmark == oldRef {
+				c.Bookmarks[i] = newRef
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	m.config.Aliases = config.Aliases
+	m.config.Bookmarks = config.Bookmarks
+	return nil
+}
+
+// jumpCursor moves the selection to row, clamped to the list and the create row
+func (m *model) jumpCursor(row int) {
+	m.cursor = max(0, min(row, len(m.filteredTries)))
+	m.adjustScroll()
+}
+
+// resort sorts the list again, keeping the selection on the same entry
+func (m *model) resort() {
+	var selected string
+	if m.cursor < len(m.filteredTries) {
+		selected = m.filteredTries[m.cursor].Path
+	}
+	m.sortTries()
+	for i, try := range m.filteredTries {
+		if try.Path == selected {
+			m.cursor = i
+		}
+	}
+	m.adjustScroll()
+}
+
+// moveCursor moves the selection by delta rows, staying within the list and the create row
+func (m *model) moveCursor(delta int) {
+	cursor := m.cursor + delta
+	if cursor < 0 || cursor > len(m.filteredTries) {
+		return
+	}
+	m.cursor = cursor
+	m.adjustScroll()
+}
+
+// typeSearch appends typed or pasted text to the search
+func (m *model) typeSearch(input string) {
+	if isValidSearchInput(input) || (m.matchMode == matchRegex && isPrintableInput(input)) {
+		m.searchTerm += input
+		m.filterTries()
+		m.cursor = 0
+		m.scrollOffset = 0
+	}
+}
+
+// action returns the selector action bound to key, or "" if none is
+func (m model) action(key string) string {
+	if key == " " {
+		// Bubble Tea reports the space bar as " ", which can't be written in a binding
+		key = "space"
+	}
+	if m.keys == nil {
+		return defaultKeymap[key]
+	}
+	return m.keys[key]
+}
+
+// keyHint names up to max of the keys bound to action, in the order they
+// were configured, for the footer and status messages: "Ctrl+N" or "Esc/q".
+// It is "" when the action is unbound, so the hint can be left out.
+func (m model) keyHint(action string, max int) string {
+	spec := defaultKeybindings[action]
+	if m.config != nil {
+		if override, ok := m.config.Keybindings[action]; ok {
+			spec = override
+		}
+	}
+	var labels []string
+	for _, key := range strings.Split(spec, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" || m.action(key) != action || len(labels) == max {
+			continue
+		}
+		labels = append(labels, keyLabel(key))
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyLabel writes a key as the help shows it, e.g. "ctrl+n" as "Ctrl+N"
+func keyLabel(key string) string {
+	switch key {
+	case "ctrl+_":
+		// What terminals send for ctrl+/
+		return "Ctrl+/"
+	case "ctrl+@":
+		return "Ctrl+Space"
+	case "pgup":
+		return "PgUp"
+	case "pgdown":
+		return "PgDn"
+	}
+	parts := strings.Split(key, "+")
+	for i, part := range parts {
+		switch {
+		case i < len(parts)-1 || len(part) > 1:
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		case len(parts) > 1:
+			parts[i] = strings.ToUpper(part)
+		}
+	}
+	return strings.Join(parts, "+")
+}
+
+// keyNote is " (<key> <text>)" with the first key bound to action, or ""
+// when it's unbound, for the end of a status message
+func (m model) keyNote(action, text string) string {
+	if key := m.keyHint(action, 1); key != "" {
+		return " (" + key + " " + text + ")"
+	}
+	return ""
+}
+
+// Update handles msg, then starts measuring any visible entry that has no
+// size yet, so the list fills in as it is scrolled and filtered
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+	next, ok := updated.(model)
+	if !ok {
+		return updated, cmd
+	}
+	if next.searchTerm != m.searchTerm {
+		// Marks made for one search shouldn't ride along, unseen, into the next
+		next.marked = nil
+	}
+	if next.measuring || next.quitting {
+		return next, cmd
+	}
+	measure := next.measureNext()
+	if measure == nil {
+		return next, cmd
+	}
+	next.measuring = true
+	return next, tea.Batch(cmd, measure)
+}
+
+func (m model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+
+	case usageMsg:
+		m.measuring = false
+		if m.usage == nil {
+			m.usage = usageCache{}
+		}
+		m.usage[msg.path] = msg.usage
+		for _, list := range [][]tryEntry{m.tries, m.filteredTries} {
+			for i := range list {
+				if list[i].Path == msg.path {
+					usage := msg.usage
+					list[i].Usage = &usage
+				}
+			}
+		}
+
+	case gitStateMsg:
+		m.measuring = false
+		if m.gitStates == nil {
+			m.gitStates = gitCache{}
+		}
+		m.gitStates[msg.path] = msg.state
+		for _, list := range [][]tryEntry{m.tries, m.filteredTries} {
+			for i := range list {
+				if list[i].Path == msg.path {
+					state := msg.state
+					list[i].Git = &state
+				}
+			}
+		}
+
+	case loopActionDoneMsg:
+		// Back in the list: record the visit and pick up any changes
+		touchPath(msg.path, m.config, true)
+		delete(m.usage, msg.path)
+		delete(m.gitStates, msg.path)
+		m.loadTries()
+		m.filterTries()
+		if m.cursor > len(m.filteredTries) {
+			m.cursor = len(m.filteredTries)
+		}
+		m.adjustScroll()
+		if msg.err != nil {
+			m.statusMsg = fmt.Sprintf("%s: %v", filepath.Base(msg.path), msg.err)
+		}
+
+	case confirmTimeoutMsg:
+		// Ignore ticks from confirmations that were already answered
+		if m.confirmDelete && msg.id == m.confirmID {
+			m.confirmDelete = false
+			m.deleteTarget = nil
+			m.deleteBatch = nil
+			m.statusMsg = "Delete cancelled (confirmation timed out)"
+		}
+
+	case tea.KeyMsg:
+		// Status messages only last until the next key press
+		m.statusMsg = ""
+
+		// Handle input mode for new directory name
+		if m.inputMode {
 			switch msg.String() {
 			case "ctrl+c", "esc":
 				m.inputMode = false
 				m.newName = ""
 
-			case "enter":
-				if m.newName != "" {
-					datePrefix := time.Now().Format("2006-01-02")
-					finalName := fmt.Sprintf("%s-%s", datePrefix, strings.ReplaceAll(m.newName, " ", "-"))
-					fullPath := filepath.Join(m.basePath, finalName)
-					m.selected = &selection{
-						Type: "mkdir",
-						Path: fullPath,
-					}
-					m.quitting = true
-					return m, tea.Quit
-				}
+			case "enter":
+				if m.newName != "" {
+					finalName := datedName(m.newName, m.config)
+					if err := validateExperimentName(finalName); err != nil {
+						// Stay in the prompt so the name can be fixed
+						m.statusMsg = "Can't create it: " + err.Error()
+						return m, nil
+					}
+					fullPath := filepath.Join(m.basePath, finalName)
+					return m.choose(&selection{
+						Type: "mkdir",
+						Path: fullPath,
+					})
+				}
+
+			case "backspace":
+				if len(m.newName) > 0 {
+					m.newName = m.newName[:len(m.newName)-1]
+				}
+
+			default:
+				// Handle character input
+				if len(msg.String()) == 1 {
+					m.newName += msg.String()
+				}
+			}
+			return m, nil
+		}
+
+		// Handle input of an alias for the highlighted entry
+		if m.aliasTarget != nil {
+			switch msg.String() {
+			case "ctrl+c", "esc":
+				m.aliasTarget = nil
+
+			case "enter":
+				m.statusMsg = m.saveAlias(*m.aliasTarget, m.aliasName)
+				m.aliasTarget = nil
+
+			case "backspace":
+				if len(m.aliasName) > 0 {
+					m.aliasName = m.aliasName[:len(m.aliasName)-1]
+				}
+
+			default:
+				if msg.Type == tea.KeyRunes && isValidAlias(m.aliasName+string(msg.Runes)) {
+					m.aliasName += string(msg.Runes)
+				}
+			}
+			return m, nil
+		}
+
+		// Handle input of a new name for the highlighted entry
+		if m.renameTarget != nil {
+			switch msg.String() {
+			case "ctrl+c", "esc":
+				m.renameTarget = nil
+
+			case "enter":
+				// On failure the prompt stays open so the name can be fixed
+				var done bool
+				m.statusMsg, done = m.renameEntry(*m.renameTarget, m.renameName)
+				if done {
+					m.renameTarget = nil
+				}
+
+			case "backspace":
+				m.renameName = dropLastRune(m.renameName)
+
+			case "ctrl+u":
+				// Clear the name but keep its date
+				if datePart, _, ok := splitDatePrefix(m.renameName, nameSeparator(m.config)); ok {
+					m.renameName = m.renameName[:len(datePart)+1]
+				} else {
+					m.renameName = ""
+				}
+
+			default:
+				if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+					m.renameName += string(msg.Runes)
+				}
+			}
+			return m, nil
+		}
+
+		// Handle input of the highlighted entry's preferred shell and editor
+		if m.metaTarget != nil {
+			field := &m.metaDraft.Shell
+			if m.metaField == 1 {
+				field = &m.metaDraft.Editor
+			}
+			switch msg.String() {
+			case "ctrl+c", "esc":
+				m.metaTarget = nil
+
+			case "enter", "tab":
+				if m.metaField == 0 {
+					m.metaField = 1
+					break
+				}
+				// A blank field means the global default, not an empty command
+				m.metaDraft.Shell = strings.TrimSpace(m.metaDraft.Shell)
+				m.metaDraft.Editor = strings.TrimSpace(m.metaDraft.Editor)
+				if err := writeMeta(m.metaTarget.Path, m.metaDraft); err != nil {
+					m.statusMsg = fmt.Sprintf("Couldn't save %s: %v", metaFileName, err)
+				} else {
+					m.statusMsg = "Saved tools for " + m.metaTarget.Basename
+					m.actions = append(m.actions, "set tools for "+m.metaTarget.Basename)
+				}
+				m.metaTarget = nil
+
+			case "backspace":
+				*field = dropLastRune(*field)
+
+			default:
+				if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+					*field += string(msg.Runes)
+				}
+			}
+			return m, nil
+		}
+
+		// Handle confirmation of restoring a trashed entry to enter it
+		if m.pendingRestore != nil {
+			entry := *m.pendingRestore
+			m.pendingRestore = nil
+			switch msg.String() {
+			case "y", "Y":
+				restored, err := m.restoreEntry(entry)
+				if err != nil {
+					m.statusMsg = fmt.Sprintf("Couldn't restore %s: %v", entry.Basename, err)
+					return m, nil
+				}
+				return m.chooseEntry(restored)
+			}
+			return m, nil
+		}
+
+		// Handle confirmation of a target outside the base path
+		if m.pendingSelect != nil {
+			sel := m.pendingSelect
+			m.pendingSelect = nil
+			switch msg.String() {
+			case "y", "Y":
+				return m.finish(sel)
+			}
+			return m, nil
+		}
+
+		// Handle delete confirmation mode
+		if m.confirmDelete && m.deleteTarget != nil {
+			key := msg.String()
+			if m.deleteByName {
+				// Only the exact name confirms; anything else typed is kept for editing
+				switch {
+				case msg.Type == tea.KeyEnter && m.deleteTyped == m.deletePhrase():
+					key = "y"
+				case msg.Type == tea.KeyBackspace:
+					m.deleteTyped = dropLastRune(m.deleteTyped)
+					return m, m.confirmTimeout()
+				case (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) && !msg.Alt:
+					m.deleteTyped += string(msg.Runes)
+					return m, m.confirmTimeout()
+				case msg.Type == tea.KeyEnter:
+					m.statusMsg = "That doesn't match; type " + m.deletePhrase() + " exactly"
+					return m, nil
+				default:
+					key = ""
+				}
+			}
+			switch key {
+			case "y", "Y":
+				if len(m.deleteBatch) > 0 {
+					m.deleteEntries(m.deleteBatch)
+					m.marked = nil
+				} else {
+					m.deleteEntries([]tryEntry{*m.deleteTarget})
+				}
+				m.confirmDelete = false
+				m.deleteTarget = nil
+				m.deleteBatch = nil
+			default:
+				// Cancel deletion on any other key
+				m.confirmDelete = false
+				m.deleteTarget = nil
+				m.deleteBatch = nil
+			}
+			return m, nil
+		}
+
+		// A focused search box takes typed text; Esc hands keys back to the list
+		if m.searchFocused {
+			if msg.Type == tea.KeyEsc {
+				m.searchFocused = false
+				return m, nil
+			}
+			if (msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace) && !msg.Alt {
+				m.typeSearch(string(msg.Runes))
+				return m, nil
+			}
+		}
+
+		// Normal mode
+		switch m.action(msg.String()) {
+		case "quit":
+			m.quitting = true
+			return m, tea.Quit
+
+		case "create":
+			// Quick create new experiment or clone
+			if m.query != "" {
+				// Check if it's a repository URL
+				isURL, cloneURL := isRepoURL(m.query)
+				if isURL {
+					// Clone repository
+					repoName := extractRepoName(cloneURL)
+					finalName := datedName(repoName, m.config)
+					fullPath := filepath.Join(m.basePath, finalName)
+					return m.choose(&selection{
+						Type:     "clone",
+						Path:     fullPath,
+						CloneURL: cloneURL,
+					})
+				} else {
+					// Regular create
+					finalName := datedName(m.query, m.config)
+					if err := validateExperimentName(finalName); err != nil {
+						m.statusMsg = "Can't create it: " + err.Error()
+						return m, nil
+					}
+					fullPath := filepath.Join(m.basePath, finalName)
+					return m.choose(&selection{
+						Type: "mkdir",
+						Path: fullPath,
+					})
+				}
+			} else {
+				// Enter input mode for new name
+				m.inputMode = true
+				m.newName = ""
+			}
+
+		case "run":
+			// Run the project's configured command in the selected directory
+			if m.cursor < len(m.filteredTries) && !m.filteredTries[m.cursor].IsFile {
+				return m.choose(&selection{
+					Type: "run",
+					Path: m.filteredTries[m.cursor].Path,
+				})
+			}
+
+		case "open_url":
+			// Open the highlighted clone's upstream page in the browser
+			if m.cursor < len(m.filteredTries) {
+				url, err := repoWebURL(m.filteredTries[m.cursor])
+				if err == nil {
+					err = openURL(url)
+				}
+				if err != nil {
+					m.statusMsg = fmt.Sprintf("Can't open URL: %v", err)
+				} else {
+					m.statusMsg = "Opened " + url
+				}
+			}
+
+		case "alias":
+			// Name a shortcut for the highlighted entry
+			if m.cursor < len(m.filteredTries) {
+				entry := m.filteredTries[m.cursor]
+				m.aliasTarget = &entry
+				m.aliasName = m.aliasOf(entry)
+			}
+
+		case "rename":
+			m.startRename()
+
+		case "tools":
+			// Pick the shell and editor this experiment opens with
+			if m.cursor < len(m.filteredTries) && !m.filteredTries[m.cursor].IsFile {
+				entry := m.filteredTries[m.cursor]
+				m.metaTarget = &entry
+				m.metaDraft = readMeta(entry.Path)
+				m.metaField = 0
+			}
+
+		case "bookmark":
+			// Add the highlighted entry to the bookmarks, or take it off
+			if m.cursor < len(m.filteredTries) {
+				m.statusMsg = m.toggleBookmark(m.filteredTries[m.cursor])
+				if m.showBookmarks {
+					m.filterTries()
+					if m.cursor > len(m.filteredTries) {
+						m.cursor = len(m.filteredTries)
+					}
+					m.adjustScroll()
+				}
+			}
+
+		case "bookmarks":
+			// Swap between every experiment and just the bookmarked ones
+			m.showBookmarks = !m.showBookmarks
+			m.filterTries()
+			m.cursor = 0
+			m.scrollOffset = 0
+			if m.showBookmarks && len(m.filteredTries) == 0 && m.searchTerm == "" {
+				m.statusMsg = "No bookmarks yet" + m.keyNote("bookmark", "bookmarks the selection")
+			}
+
+		case "pin":
+			// Keep the highlighted entry at the top, or let it go back
+			if m.cursor < len(m.filteredTries) {
+				m.statusMsg = m.togglePin(m.filteredTries[m.cursor])
+				m.resort()
+			}
+
+		case "sort":
+			// Cycle through the sort modes
+			next := 0
+			for i, mode := range sortModes {
+				if mode == m.sortMode {
+					next = (i + 1) % len(sortModes)
+				}
+			}
+			m.sortMode = sortModes[next]
+			m.resort()
+
+		case "preview":
+			m.hidePreview = !m.hidePreview
+			if !m.hidePreview && !m.previewFits() {
+				m.statusMsg = fmt.Sprintf("The preview needs a terminal at least %d columns wide", previewMinWidth)
+			}
+
+		case "paste":
+			// Search for the clipboard, so a copied URL offers a clone and a name a create
+			text, err := readClipboard()
+			if err == nil && clipboardSearch(text) == "" {
+				err = fmt.Errorf("clipboard is empty")
+			}
+			if err != nil {
+				m.statusMsg = fmt.Sprintf("Can't paste: %v", err)
+				break
+			}
+			m.searchTerm = clipboardSearch(text)
+			m.filterTries()
+			m.cursor = 0
+			m.scrollOffset = 0
+
+		case "root":
+			// Open the base path itself rather than one experiment
+			return m.choose(&selection{
+				Type: "root",
+				Path: m.basePath,
+			})
+
+		case "delete":
+			// Delete the marked entries, or the highlighted one, with confirmation
+			if batch := m.markedEntries(); len(batch) > 0 {
+				m.confirmDelete = true
+				m.deleteBatch = batch
+				m.deleteTarget = &batch[0]
+				m.deleteTyped = ""
+				m.deleteByName = false
+				for _, entry := range batch {
+					m.deleteByName = m.deleteByName || needsTypedConfirm(entry)
+				}
+				return m, m.confirmTimeout()
+			}
+			if m.cursor < len(m.filteredTries) && m.filteredTries[m.cursor].Trashed {
+				m.statusMsg = "Already in the trash (try --empty-trash removes it for good)"
+			} else if m.cursor < len(m.filteredTries) {
+				m.confirmDelete = true
+				entry := m.filteredTries[m.cursor]
+				m.deleteTarget = &entry
+				m.deleteTyped = ""
+				m.deleteByName = needsTypedConfirm(entry)
+				return m, m.confirmTimeout()
+			}
+
+		case "mark":
+			// Mark the highlighted entry for a batch delete and move on to the next
+			if m.cursor < len(m.filteredTries) && m.filteredTries[m.cursor].Trashed {
+				m.statusMsg = "Already in the trash"
+			} else if m.cursor < len(m.filteredTries) {
+				path := m.filteredTries[m.cursor].Path
+				if m.marked[path] {
+					delete(m.marked, path)
+				} else {
+					if m.marked == nil {
+						m.marked = make(map[string]bool)
+					}
+					m.marked[path] = true
+				}
+				if count := len(m.markedEntries()); count > 0 {
+					m.statusMsg = fmt.Sprintf("%d marked", count) + m.keyNote("delete", "deletes them")
+				}
+				m.moveCursor(1)
+			}
+
+		case "undo":
+			m.undoDelete()
+
+		case "trash":
+			m.toggleTrash()
+
+		case "select":
+			if m.cursor < len(m.filteredTries) {
+				return m.chooseEntry(m.filteredTries[m.cursor])
+			} else if m.cursor == len(m.filteredTries) {
+				// Plain enter opens what was clearly meant; Ctrl+N still forces creation
+				if top, ok := m.strongMatch(); ok {
+					return m.chooseEntry(top)
+				}
+
+				// Create new directory or clone repository
+				if m.query != "" {
+					// Check if it's a repository URL
+					isURL, cloneURL := isRepoURL(m.query)
+					if isURL && m.existingClone != nil {
+						return m.chooseEntry(*m.existingClone)
+					} else if isURL {
+						// Clone repository
+						repoName := extractRepoName(cloneURL)
+						finalName := datedName(repoName, m.config)
+						fullPath := filepath.Join(m.basePath, finalName)
+						return m.choose(&selection{
+							Type:     "clone",
+							Path:     fullPath,
+							CloneURL: cloneURL,
+						})
+					} else {
+						// Regular create
+						finalName := datedName(m.query, m.config)
+						if err := validateExperimentName(finalName); err != nil {
+							m.statusMsg = "Can't create it: " + err.Error()
+							return m, nil
+						}
+						fullPath := filepath.Join(m.basePath, finalName)
+						return m.choose(&selection{
+							Type: "mkdir",
+							Path: fullPath,
+						})
+					}
+				} else {
+					// Enter input mode for new name
+					m.inputMode = true
+					m.newName = ""
+				}
+			}
+
+		case "up":
+			m.moveCursor(-1)
+
+		case "down":
+			m.moveCursor(1)
+
+		case "page_up":
+			m.jumpCursor(m.cursor - m.maxVisible())
+
+		case "page_down":
+			m.jumpCursor(m.cursor + m.maxVisible())
+
+		case "first":
+			m.jumpCursor(0)
+
+		case "last":
+			// The create row
+			m.jumpCursor(len(m.filteredTries))
+
+		case "erase":
+			if len(m.searchTerm) > 0 {
+				m.searchTerm = dropLastRune(m.searchTerm)
+				m.filterTries()
+				m.cursor = 0
+				m.scrollOffset = 0
+			}
+
+		case "match_mode":
+			// Cycle fuzzy -> substring -> regex
+			m.matchMode = (m.matchMode + 1) % len(matchModeNames)
+			m.filterTries()
+			m.cursor = 0
+			m.scrollOffset = 0
+
+		case "regex":
+			// Straight into regex matching and back to fuzzy
+			if m.matchMode == matchRegex {
+				m.matchMode = matchFuzzy
+			} else {
+				m.matchMode = matchRegex
+			}
+			m.filterTries()
+			m.cursor = 0
+			m.scrollOffset = 0
+
+		case "clear_search":
+			m.searchTerm = ""
+			m.filterTries()
+			m.cursor = 0
+			m.scrollOffset = 0
+
+		default:
+			// Alt+1-9 picks a numbered row straight away
+			if key := msg.String(); len(key) == 5 && strings.HasPrefix(key, "alt+") && key[4] >= '1' && key[4] <= '9' {
+				if m.config != nil && m.config.NumberSelect {
+					if idx, ok := m.numberedEntry(int(key[4] - '0')); ok {
+						return m.chooseEntry(m.filteredTries[idx])
+					}
+				}
+				return m, nil
+			}
+
+			// Typing only searches in always mode; in explicit mode keys navigate until / is pressed
+			navigating := m.config != nil && m.config.SearchMode == "explicit" && !m.searchFocused
+
+			// With an empty search, digits jump to the numbered row instead of searching
+			if m.config != nil && m.config.NumberSelect && (m.searchTerm == "" || navigating) && msg.Type == tea.KeyRunes &&
+				len(msg.Runes) == 1 && msg.Runes[0] >= '1' && msg.Runes[0] <= '9' {
+				if idx, ok := m.numberedEntry(int(msg.Runes[0] - '0')); ok {
+					m.cursor = idx
+				}
+				return m, nil
+			}
+
+			if navigating {
+				switch msg.String() {
+				case "/":
+					m.searchFocused = true
+				case "j":
+					m.moveCursor(1)
+				case "k":
+					m.moveCursor(-1)
+				case "r":
+					m.startRename()
+				case "u":
+					m.undoDelete()
+				case "p":
+					if m.cursor < len(m.filteredTries) {
+						m.statusMsg = m.togglePin(m.filteredTries[m.cursor])
+						m.resort()
+					}
+				}
+				return m, nil
+			}
+
+			// Handle character input for search (including paste); a lone
+			// space arrives as KeySpace, so words can be typed apart
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				m.typeSearch(string(msg.Runes))
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Below either of these the list switches to the compact layout
+const (
+	compactHeight = 14
+	compactWidth  = 60
+)
+
+// compact reports whether the terminal is too small for the full layout
+func (m model) compact() bool {
+	return m.height < compactHeight || m.width < compactWidth
+}
+
+// The preview pane only appears next to the list from this width on
+const (
+	previewMinWidth = 100
+	previewMaxFiles = 15
+	// Beyond this many entries preview_sort mtime keeps name order rather
+	// than stat every one of them
+	previewStatLimit = 500
+)
+
+// previewCache holds the preview lines of each path shown so far
+type previewCache map[string][]string
+
+func (m model) previewFits() bool {
+	return m.width >= previewMinWidth && !m.compact()
+}
+
+// previewLines describes what's in entry for the preview pane: a directory's
+// top-level contents, directories first, as a small tree. preview_sort and
+// preview_show_size only stat the entries they need.
+func (m model) previewLines(entry tryEntry) []string {
+	if lines, ok := m.previews[entry.Path]; ok {
+		return lines
+	}
+
+	var lines []string
+	if entry.IsFile {
+		if info, err := os.Stat(entry.Path); err == nil {
+			lines = []string{fmt.Sprintf("Single file, %s", formatSize(info.Size()))}
+		}
+	} else if entries, err := os.ReadDir(entry.Path); err != nil {
+		lines = []string{fmt.Sprintf("Can't read it: %v", err)}
+	} else if len(entries) == 0 {
+		lines = []string{"(empty)"}
+	} else {
+		var mtimes map[string]time.Time
+		if m.config != nil && m.config.PreviewSort == "mtime" && len(entries) <= previewStatLimit {
+			mtimes = make(map[string]time.Time, len(entries))
+			for _, e := range entries {
+				if info, err := e.Info(); err == nil {
+					mtimes[e.Name()] = info.ModTime()
+				}
+			}
+		}
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].IsDir() != entries[j].IsDir() {
+				return entries[i].IsDir()
+			}
+			return mtimes[entries[i].Name()].After(mtimes[entries[j].Name()])
+		})
+		shown := entries
+		if len(shown) > previewMaxFiles {
+			shown = shown[:previewMaxFiles-1]
+		}
+		for i, e := range shown {
+			branch := "├── "
+			if i == len(entries)-1 {
+				branch = "└── "
+			}
+			name := cleanDisplayName(e.Name())
+			if e.IsDir() {
+				name += "/"
+			} else if m.config != nil && m.config.PreviewShowSize {
+				if info, err := e.Info(); err == nil {
+					name += "  " + formatSize(info.Size())
+				}
+			}
+			lines = append(lines, branch+name)
+		}
+		if len(shown) < len(entries) {
+			lines = append(lines, fmt.Sprintf("└── … %d more", len(entries)-len(shown)))
+		}
+	}
+
+	if m.previews != nil {
+		m.previews[entry.Path] = lines
+	}
+	return lines
+}
+
+// renderPreview draws the preview pane for the entry under the cursor,
+// at most height lines tall
+func (m model) renderPreview(width, height int) string {
+	var lines []string
+	if m.cursor < len(m.filteredTries) {
+		entry := m.filteredTries[m.cursor]
+		lines = append(lines, searchStyle.Render(truncateWidth(cleanDisplayName(entry.Basename), width-2)))
+		for _, line := range m.previewLines(entry) {
+			lines = append(lines, dimStyle.Render(truncateWidth(line, width-2)))
+		}
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	pane := lipgloss.NewStyle().
+		Width(width - 1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(lipgloss.Color("240")).
+		PaddingLeft(1)
+	return pane.Render(strings.Join(lines, "\n"))
+}
+
+// maxVisible returns how many list rows fit around the header and footer
+func (m model) maxVisible() int {
+	if m.compact() {
+		// Title, search line, the blank before "Create new" and one help line
+		if n := m.height - 4; n > 1 {
+			return n
+		}
+		return 1
+	}
+	// Accounting for the help lines and separators
+	if n := m.height - 10; n > 3 {
+		return n
+	}
+	return 3
+}
+
+// chooseEntry selects an existing directory, or opens a single-file experiment
+func (m model) chooseEntry(entry tryEntry) (tea.Model, tea.Cmd) {
+	if entry.Trashed {
+		// Only entered once it's back where it came from
+		m.pendingRestore = &entry
+		return m, nil
+	}
+	action := "cd"
+	if entry.IsFile {
+		action = "edit"
+	} else if m.runOnSelect {
+		action = "run"
+	}
+	return m.choose(&selection{
+		Type: action,
+		Path: entry.Path,
+	})
+}
+
+// strongMatch returns the top result when EnterPrefersExisting is set and its
+// name (ignoring the date) equals or starts with the search
+func (m model) strongMatch() (tryEntry, bool) {
+	if m.config == nil || !m.config.EnterPrefersExisting || m.query == "" || len(m.filteredTries) == 0 {
+		return tryEntry{}, false
+	}
+	top := m.filteredTries[0]
+	sep := nameSeparator(m.config)
+	query := strings.ToLower(strings.ReplaceAll(m.query, " ", sep))
+	return top, strings.HasPrefix(undatedName(top.Basename, sep), query)
+}
+
+// enterHint describes what Enter does right now, for the footer
+func (m model) enterHint() string {
+	if m.cursor < len(m.filteredTries) {
+		if m.filteredTries[m.cursor].Trashed {
+			return "Restore"
+		}
+		if m.filteredTries[m.cursor].IsFile {
+			return "Edit"
+		}
+		return "Open"
+	}
+	if top, ok := m.strongMatch(); ok {
+		return "Open " + truncateWidth(cleanDisplayName(top.Basename), 24)
+	}
+	if isURL, _ := isRepoURL(m.query); isURL {
+		if m.existingClone != nil {
+			return "Open " + truncateWidth(cleanDisplayName(m.existingClone.Basename), 24)
+		}
+		return "Clone"
+	}
+	if m.query != "" {
+		return "Create"
+	}
+	return "New"
+}
+
+// numberedEntry returns the index into filteredTries of the nth (1-based)
+// visible row, if that row is an experiment
+func (m model) numberedEntry(n int) (int, bool) {
+	idx := m.scrollOffset + n - 1
+	if n < 1 || n > m.maxVisible() || idx >= len(m.filteredTries) {
+		return 0, false
+	}
+	return idx, true
+}
+
+// choose finishes the session with sel, first asking for confirmation when
+// ConfirmOutsideBase is set and sel would enter or create outside basePath
+func (m model) choose(sel *selection) (tea.Model, tea.Cmd) {
+	if m.config != nil && m.config.ConfirmOutsideBase && (sel.Type == "cd" || sel.Type == "mkdir") && !isUnder(m.basePath, sel.Path) {
+		m.pendingSelect = sel
+		return m, nil
+	}
+	return m.finish(sel)
+}
+
+// finish acts on a confirmed selection: normally by quitting so main can
+// handle it, but in loop mode opening an entry returns to the list afterwards
+func (m model) finish(sel *selection) (tea.Model, tea.Cmd) {
+	if m.loop && (sel.Type == "cd" || sel.Type == "edit" || sel.Type == "run") {
+		return m.loopAction(sel)
+	}
+	m.selected = sel
+	m.quitting = true
+	return m, tea.Quit
+}
+
+// usageMsg carries the size measured for the entry at path
+type usageMsg struct {
+	path  string
+	usage dirUsage
+}
+
+// gitStateMsg carries the git state read for the checkout at path
+type gitStateMsg struct {
+	path  string
+	state gitState
+}
+
+// measureNext returns a command measuring the first visible entry without a
+// size, or with show_git_status reading the first visible checkout without a
+// git state, or nil if there's nothing left. Entries are measured one at a time.
+func (m model) measureNext() tea.Cmd {
+	end := m.scrollOffset + m.maxVisible()
+	if end > len(m.filteredTries) {
+		end = len(m.filteredTries)
+	}
+	showGit := m.config != nil && m.config.ShowGitStatus
+	for idx := m.scrollOffset; idx < end; idx++ {
+		entry := m.filteredTries[idx]
+		path := entry.Path
+		if _, ok := m.usage[path]; !ok {
+			return func() tea.Msg {
+				return usageMsg{path: path, usage: measureUsage(path)}
+			}
+		}
+		if _, ok := m.gitStates[path]; showGit && entry.IsRepo && !ok {
+			return func() tea.Msg {
+				return gitStateMsg{path: path, state: readGitState(path)}
+			}
+		}
+	}
+	return nil
+}
+
+// loopActionDoneMsg reports that a loop-mode action over path has finished
+type loopActionDoneMsg struct {
+	path string
+	err  error
+}
+
+// loopAction runs the configured loop_action for sel while the TUI is suspended
+func (m model) loopAction(sel *selection) (tea.Model, tea.Cmd) {
+	action := ""
+	if m.config != nil {
+		action = m.config.LoopAction
+	}
+
+	name := filepath.Base(sel.Path)
+	var cmd *exec.Cmd
+	switch {
+	case action == "print":
+		// Collected and written to stdout when try exits
+		m.loopPrinted = append(m.loopPrinted, sel.Path)
+		m.actions = append(m.actions, "picked "+name)
+		m.statusMsg = "Picked " + filepath.Base(sel.Path)
+		return m, nil
+	case action == "editor" || sel.Type == "edit":
+		var err error
+		if cmd, err = editorCommand(sel.Path, m.config); err != nil {
+			m.statusMsg = fmt.Sprintf("Can't open %s: %v", name, err)
+			return m, nil
+		}
+		m.actions = append(m.actions, "edited "+name)
+	case sel.Type == "run":
+		kind := detectProjectType(sel.Path)
+		var command string
+		if m.config != nil {
+			command = m.config.RunCommands[kind]
+		}
+		if command == "" {
+			m.statusMsg = fmt.Sprintf("No run command for %s", name)
+			return m, nil
+		}
+		cmd = exec.Command(getShell(m.config), "-c", command)
+		m.actions = append(m.actions, "ran "+name)
+	case action == "" || action == "shell":
+		m.actions = append(m.actions, "opened "+name)
+		var cleanup func()
+		cmd, cleanup = shellCommand(sel.Path, m.config)
+		path := sel.Path
+		return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+			cleanup()
+			return loopActionDoneMsg{path: path, err: err}
+		})
+	default:
+		// Anything else is a command for the shell to run in the experiment
+		cmd = exec.Command(getShell(m.config), "-c", action)
+		m.actions = append(m.actions, "ran "+name)
+	}
+	if sel.Type != "edit" {
+		cmd.Dir = sel.Path
+	}
+
+	path := sel.Path
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return loopActionDoneMsg{path: path, err: err}
+	})
+}
+
+// confirmTimeout starts a new confirmation and, when ConfirmTimeout is set,
+// schedules its automatic cancellation
+func (m *model) confirmTimeout() tea.Cmd {
+	m.confirmID++
+	if m.config == nil || m.config.ConfirmTimeout <= 0 {
+		return nil
+	}
+	id := m.confirmID
+	return tea.Tick(time.Duration(m.config.ConfirmTimeout)*time.Second, func(time.Time) tea.Msg {
+		return confirmTimeoutMsg{id: id}
+	})
+}
+
+func (m *model) adjustScroll() {
+	maxVisible := m.maxVisible()
+
+	if m.cursor < m.scrollOffset {
+		m.scrollOffset = m.cursor
+	} else if m.cursor >= m.scrollOffset+maxVisible {
+		m.scrollOffset = m.cursor - maxVisible + 1
+	}
+}
+
+func (m model) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+
+	compact := m.compact()
+
+	// Title
+	switch {
+	case m.showBookmarks && compact:
+		b.WriteString(titleStyle.MarginBottom(0).Render(icon("star") + " Bookmarks"))
+	case m.showBookmarks:
+		b.WriteString(titleStyle.Render(icon("star") + " Try - Bookmarks"))
+	case m.grep != nil && compact:
+		b.WriteString(titleStyle.MarginBottom(0).Render(icon("dir") + " grep " + m.grep.String()))
+	case m.grep != nil:
+		b.WriteString(titleStyle.Render(icon("dir") + " Try - Files Matching " + m.grep.String()))
+	case !m.since.IsZero() && compact:
+		b.WriteString(titleStyle.MarginBottom(0).Render(icon("dir") + " Since last run"))
+	case !m.since.IsZero():
+		b.WriteString(titleStyle.Render(icon("dir") + " Try - Changed Since " + m.since.Format("Jan 2 15:04")))
+	case compact:
+		b.WriteString(titleStyle.MarginBottom(0).Render(icon("dir") + " Try"))
+	default:
+		b.WriteString(titleStyle.Render(icon("dir") + " Try - Quick Experiment Directories"))
+	}
+	b.WriteString("\n")
+
+	// Handle delete confirmation mode
+	if m.confirmDelete && len(m.deleteBatch) > 0 {
+		b.WriteString("\n")
+		b.WriteString(dangerStyle.Render(fmt.Sprintf("%s Delete %d Directories", icon("warning"), len(m.deleteBatch))))
+		b.WriteString("\n\n")
+		b.WriteString("Are you sure you want to delete these directories?\n\n")
+		// Leave room for the header and the prompt below
+		shown := len(m.deleteBatch)
+		if limit := m.height - 14; m.height > 0 && shown > limit {
+			shown = max(limit, 1)
+		}
+		for _, entry := range m.deleteBatch[:shown] {
+			b.WriteString(warningStyle.Render("  " + entry.Name))
+			b.WriteString("\n")
+		}
+		if shown < len(m.deleteBatch) {
+			b.WriteString(dimStyle.Render(fmt.Sprintf("  ...and %d more", len(m.deleteBatch)-shown)))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	} else if m.confirmDelete && m.deleteTarget != nil {
+		b.WriteString("\n")
+		b.WriteString(dangerStyle.Render(icon("warning") + " Delete Directory"))
+		b.WriteString("\n\n")
+		b.WriteString("Are you sure you want to delete this directory?\n\n")
+		b.WriteString(warningStyle.Render("  " + m.deleteTarget.Name))
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("  " + m.deleteTarget.Path))
+		b.WriteString("\n\n")
+	}
+	if m.confirmDelete && m.deleteTarget != nil {
+		undo := ""
+		if key := m.keyHint("undo", 1); key != "" {
+			undo = "; " + key + " brings it back,"
+		}
+		b.WriteString(dimStyle.Render("It goes to the trash" + undo + " try --empty-trash removes it for good"))
+		b.WriteString("\n\n")
+		if m.deleteByName {
+			if len(m.deleteBatch) > 0 {
+				b.WriteString("Type " + m.deletePhrase() + " to confirm:\n")
+			} else {
+				b.WriteString("Type the name to confirm:\n")
+			}
+			b.WriteString(searchInputStyle.Render(m.deleteTyped))
+			b.WriteString("\n\n")
+			if m.statusMsg != "" {
+				b.WriteString(warningStyle.Render(m.statusMsg))
+				b.WriteString("\n")
+			}
+			b.WriteString(helpStyle.Render("Enter: Delete • Esc: Cancel"))
+			return b.String()
+		}
+		b.WriteString(helpStyle.Render("Press 'y' to confirm, any other key to cancel"))
+		return b.String()
+	}
+
+	// Handle confirmation of restoring a trashed entry
+	if m.pendingRestore != nil {
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render(icon("trash") + " In the Trash"))
+		b.WriteString("\n\n")
+		b.WriteString(fmt.Sprintf("Restore %s and open it?\n\n", cleanDisplayName(m.pendingRestore.Basename)))
+		b.WriteString(dimStyle.Render("  " + filepath.Join(m.pendingRestore.Root, m.pendingRestore.Name)))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Press 'y' to restore, any other key to go back"))
+		return b.String()
+	}
+
+	// Handle confirmation of a target outside the base path
+	if m.pendingSelect != nil {
+		action := "Enter"
+		if m.pendingSelect.Type == "mkdir" {
+			action = "Create"
+		}
+		b.WriteString("\n")
+		b.WriteString(warningStyle.Render(icon("warning") + " Outside Base Path"))
+		b.WriteString("\n\n")
+		b.WriteString(fmt.Sprintf("%s a directory outside %s?\n\n", action, m.basePath))
+		b.WriteString(dimStyle.Render("  " + m.pendingSelect.Path))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Press 'y' to continue, any other key to go back"))
+		return b.String()
+	}
+
+	// Handle input of an experiment's preferred tools
+	if m.metaTarget != nil {
+		b.WriteString("\n")
+		b.WriteString(promptStyle.Render("Tools for " + m.metaTarget.Basename))
+		b.WriteString("\n\n")
+		fields := []struct{ label, value, hint string }{
+			{"Shell:  ", m.metaDraft.Shell, "default " + filepath.Base(getShell(m.config))},
+			{"Editor: ", m.metaDraft.Editor, "default " + getEditor(m.config)},
+		}
+		for i, field := range fields {
+			b.WriteString(field.label)
+			b.WriteString(searchInputStyle.Render(field.value))
+			if i == m.metaField {
+				b.WriteString(searchInputStyle.Render("_"))
+			}
+			if field.value == "" {
+				b.WriteString(dimStyle.Render(" (" + field.hint + ")"))
+			}
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+		b.WriteString(helpStyle.Render("Enter: Next/Save (empty uses the default)  ESC: Cancel"))
+		return b.String()
+	}
+
+	// Handle input of a new name
+	if m.renameTarget != nil {
+		b.WriteString("\n")
+		b.WriteString(promptStyle.Render("Rename " + m.renameTarget.Basename + " to:"))
+		b.WriteString("\n")
+		b.WriteString(searchInputStyle.Render(m.renameName))
+		b.WriteString("\n\n")
+		if m.statusMsg != "" {
+			b.WriteString(warningStyle.Render(m.statusMsg))
+			b.WriteString("\n")
+		}
+		b.WriteString(helpStyle.Render("Enter: Rename  Ctrl+U: Clear name  ESC: Cancel"))
+		return b.String()
+	}
+
+	// Handle input of an alias
+	if m.aliasTarget != nil {
+		b.WriteString("\n")
+		b.WriteString(promptStyle.Render("Alias for " + m.aliasTarget.Basename + ":"))
+		b.WriteString("\n")
+		b.WriteString(searchInputStyle.Render(m.aliasName))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render("Enter: Save (empty removes)  ESC: Cancel"))
+		return b.String()
+	}
+
+	// Handle input mode for new directory
+	if m.inputMode {
+		b.WriteString("\n")
+		b.WriteString(promptStyle.Render("New directory name:"))
+		b.WriteString("\n")
+		datePrefix := time.Now().Format("2006-01-02")
+		b.WriteString(dimStyle.Render(datePrefix + nameSeparator(m.config)))
+		b.WriteString(searchInputStyle.Render(m.newName))
+		b.WriteString("\n\n")
+		if m.statusMsg != "" {
+			b.WriteString(warningStyle.Render(m.statusMsg))
+			b.WriteString("\n")
+		}
+		b.WriteString(helpStyle.Render("Enter: Create  ESC: Cancel"))
+		return b.String()
+	}
+
+	separator := func() {
+		if !compact {
+			b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width-1)))
+			b.WriteString("\n")
+		}
+	}
+
+	separator()
+
+	// Search input
+	explicit := m.config != nil && m.config.SearchMode == "explicit"
+	if m.matchMode == matchRegex {
+		// Slashes around the pattern make the mode hard to miss
+		b.WriteString(searchStyle.Render("Regex: "))
+		b.WriteString(dimStyle.Render("/") + searchInputStyle.Render(m.searchTerm))
+		if m.searchFocused {
+			b.WriteString(searchInputStyle.Render("_"))
+		}
+		b.WriteString(dimStyle.Render("/"))
+	} else {
+		b.WriteString(searchStyle.Render("Search: "))
+		b.WriteString(searchInputStyle.Render(m.searchTerm))
+		if m.searchFocused {
+			b.WriteString(searchInputStyle.Render("_"))
+		}
+	}
+	if m.regexErr != nil {
+		b.WriteString(warningStyle.Render(" " + regexProblem(m.regexErr)))
+	}
+	if !compact {
+		switch {
+		case explicit && !m.searchFocused:
+			b.WriteString(dimStyle.Render(" (/ to search)"))
+		case explicit:
+			b.WriteString(dimStyle.Render(" (Esc to leave search)"))
+		case m.searchTerm == "":
+			b.WriteString(dimStyle.Render(" (type to filter)"))
+		}
+	}
+	b.WriteString("\n")
+	separator()
+
+	// Say why the list is empty when the base path can't be read; the create row stays usable
+	var rootErr *rootError
+	if len(m.tries) == 0 && errors.As(m.loadErr, &rootErr) {
+		b.WriteString(warningStyle.Render(icon("warning") + " " + truncateWidth(rootErr.Problem, m.width-4)))
+		b.WriteString("\n")
+		b.WriteString(dimStyle.Render("  " + truncateWidth(rootErr.Hint, m.width-2)))
+		b.WriteString("\n\n")
+	}
+
+	// Calculate visible window
+	maxVisible := m.maxVisible()
+	totalItems := len(m.filteredTries) + 1
+
+	// Display items
+	visibleEnd := m.scrollOffset + maxVisible
+	if visibleEnd > totalItems {
+		visibleEnd = totalItems
+	}
+
+	// With the preview pane the rows are laid out in what's left of the width
+	list := m
+	previewWidth := 0
+	if !m.hidePreview && m.previewFits() {
+		previewWidth = m.width / 3
+		// Rows run one column past their width (the space after the icon isn't
+		// counted), and one more separates them from the pane
+		list.width = m.width - previewWidth - 2
+	}
+	var rows strings.Builder
+
+	for idx := m.scrollOffset; idx < visibleEnd; idx++ {
+		// Add blank line before "Create new"
+		if idx == len(m.filteredTries) && len(m.filteredTries) > 0 {
+			rows.WriteString("\n")
+		}
+
+		// Cursor, or the row's number for quick selection
+		isSelected := idx == m.cursor
+		row := idx - m.scrollOffset + 1
+		if isSelected {
+			rows.WriteString(cursorStyle.Render("→ "))
+		} else if m.config != nil && m.config.NumberSelect && row <= 9 && idx < len(m.filteredTries) {
+			rows.WriteString(dimStyle.Render(fmt.Sprintf("%d ", row)))
+		} else {
+			rows.WriteString("  ")
+		}
+
+		// Display entry
+		if idx < len(m.filteredTries) {
+			entry := m.filteredTries[idx]
+			line := list.formatEntry(entry, isSelected)
+			rows.WriteString(line)
+		} else {
+			// Create new option
+			line := list.formatCreateNew(isSelected)
+			rows.WriteString(line)
+		}
+		rows.WriteString("\n")
+	}
+
+	if previewWidth > 0 {
+		listBlock := strings.TrimSuffix(rows.String(), "\n")
+		preview := m.renderPreview(previewWidth, maxVisible+1)
+		b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, listBlock, " ", preview))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(rows.String())
+	}
+
+	// Scroll indicator
+	scrollText := ""
+	if totalItems > maxVisible {
+		scrollText = fmt.Sprintf("[%d-%d/%d]", m.scrollOffset+1, visibleEnd, totalItems)
+		if !compact {
+			separator()
+			b.WriteString(dimStyle.Render(scrollText))
+			b.WriteString("\n")
+		}
+	}
+
+	separator()
+	if m.statusMsg != "" {
+		b.WriteString(warningStyle.Render(m.statusMsg))
+		b.WriteString("\n")
+	}
+
+	// A single help line, with the scroll position folded in
+	hints := func(sep string, short bool, pairs ...string) string {
+		var parts []string
+		for i := 0; i+1 < len(pairs); i += 2 {
+			max := 1
+			if pairs[i] == "quit" {
+				max = 2
+			}
+			key := m.keyHint(pairs[i], max)
+			if key == "" {
+				continue
+			}
+			if short {
+				key = strings.ReplaceAll(key, "Ctrl+", "^")
+				parts = append(parts, key+":"+pairs[i+1])
+			} else {
+				parts = append(parts, key+": "+pairs[i+1])
+			}
+		}
+		return strings.Join(parts, sep)
+	}
+
+	if compact {
+		help := hints(" ", true, "select", m.enterHint(), "create", "New", "delete", "Del", "quit", "Quit")
+		if scrollText != "" && len(scrollText)+1+len(help) < m.width {
+			help = scrollText + " " + help
+		}
+		b.WriteString(helpStyle.Render(help))
+		return b.String()
+	}
+
+	// Navigation hints
+	b.WriteString(helpStyle.Render("↑↓/Ctrl+j,k: Navigate " + hints(" ", false, "select", m.enterHint(), "create", "Quick new", "delete", "Delete")))
+	b.WriteString("\n")
+	// Action hints
+	modeText := matchModeNames[m.matchMode]
+	if m.regexErr != nil {
+		modeText += " (invalid)"
+	}
+	sortText := m.sortMode
+	if sortText == "" {
+		sortText = sortModes[0]
+	}
+	actionHints := hints("  ", false, "match_mode", "Match mode ["+modeText+"]", "sort", "Sort ["+sortText+"]", "quit", "Quit")
+	if explicit {
+		actionHints = "/: Search  " + actionHints
+	}
+	b.WriteString(helpStyle.Render(actionHints))
+
+	return b.String()
+}
+
+func (m model) formatEntry(entry tryEntry, isSelected bool) string {
+	var result strings.Builder
+
+	// Icon
+	entryIcon := icon("dir")
+	if entry.IsFile {
+		entryIcon = icon("file")
+	}
+	if entry.Trashed {
+		entryIcon = icon("trash")
+	}
+	if m.marked[entry.Path] {
+		entryIcon = icon("mark")
+	}
+	result.WriteString(entryIcon + " ")
+
+	// Parse and format the name; only the display is cleaned up, the path is untouched
+	name := cleanDisplayName(entry.Basename)
+	var displayName string
+
+	datePart, namePart, ok := splitDatePrefix(name, nameSeparator(m.config))
+	dateSep := "-"
+	if ok {
+		// Show the separator the name actually uses
+		dateSep = name[10:11]
+	}
+	addedWidth := 0 // Columns added on top of the name itself
+	if m.config != nil && m.config.FilesystemDates {
+		// The filesystem knows when the directory was made, whatever its name says
+		if !ok {
+			namePart, ok = name, true
+			dateSep = " "
+			addedWidth = len("2006-01-02 ")
+		}
+		datePart = entry.CTime.Format("2006-01-02")
+	}
+
+	// Age coloring tints unselected rows by how recently they were used
+	entryDateStyle, nameStyle := dateStyle, lipgloss.NewStyle()
+	if m.config != nil && m.config.AgeColoring && !isSelected {
+		nameStyle = ageStyle(entry.MTime, time.Now())
+		entryDateStyle = nameStyle
+	}
+
+	if ok {
+		// Date-prefixed format
+		if isSelected {
+			displayName = selectedStyle.Render(
+				dateStyle.Render(datePart) +
+					dimStyle.Render(dateSep) +
+					m.highlightMatches(namePart))
+		} else {
+			displayName = entryDateStyle.Render(datePart) +
+				dimStyle.Render(dateSep) +
+				m.highlightMatchesIn(namePart, nameStyle)
+		}
+	} else {
+		// Regular name
+		if isSelected {
+			displayName = selectedStyle.Render(m.highlightMatches(name))
+		} else {
+			displayName = m.highlightMatchesIn(name, nameStyle)
+		}
+	}
+
+	result.WriteString(displayName)
+
+	// Flag names that also appear under another date
+	dupText := ""
+	if entry.Duplicate {
+		dupText = " (dup)"
+		result.WriteString(dimStyle.Render(dupText))
+	}
+	pinText := ""
+	if m.isPinned(entry) {
+		pinText = " " + icon("pin")
+		result.WriteString(pinText)
+	}
+
+	// Add metadata (size, time and score)
+	timeText := m.formatRelativeTime(entry.MTime)
+	scoreText := fmt.Sprintf("%.*f", m.scorePrecision, entry.Score)
+	metaText := fmt.Sprintf(" %s, score: %s", timeText, scoreText)
+	if m.sortMode != "" && m.sortMode != "score" {
+		// The score doesn't decide the order here, so don't suggest it does
+		metaText = " " + timeText
+	}
+	if entry.Usage != nil {
+		metaText = " " + entry.Usage.String() + "," + metaText
+	}
+	if entry.Git != nil && entry.Git.Branch != "" {
+		metaText = " " + entry.Git.String() + "," + metaText
+	}
+	if entry.HitFile != "" {
+		// Where the search found it matters more than the size or score
+		matches := "matches"
+		if entry.Hits == 1 {
+			matches = "match"
+		}
+		metaText = fmt.Sprintf(" %d %s (%s), %s", entry.Hits, matches, entry.HitFile, timeText)
+	}
+	if m.compact() {
+		// Only the age fits next to the name in a small pane
+		metaText = " " + timeText
+	} else if len(m.roots) > 1 {
+		// Say which root the entry lives in when browsing several
+		metaText = fmt.Sprintf(" [%s]%s", filepath.Base(entry.Root), metaText)
+	}
+
+	// Calculate padding
+	plainTextLen := lipgloss.Width(name) + addedWidth + lipgloss.Width(entryIcon) + len(dupText) + lipgloss.Width(pinText)
+	metaLen := lipgloss.Width(metaText)
+	paddingNeeded := m.width - 2 - plainTextLen - metaLen // -2 for cursor space
+	if paddingNeeded > 0 {
+		result.WriteString(strings.Repeat(" ", paddingNeeded))
+	}
+
+	result.WriteString(dimStyle.Render(metaText))
+
+	return result.String()
+}
+
+func (m model) formatCreateNew(isSelected bool) string {
+	var result strings.Builder
+	var displayText string
+	var iconLen int
+
+	// Check if search term is a repository URL
+	isURL, cloneURL := isRepoURL(m.query)
+
+	if isURL {
+		result.WriteString(icon("clone") + " ")
+		iconLen = lipgloss.Width(icon("clone"))
+		repoName := extractRepoName(cloneURL)
+		displayText = m.fitCreateText(fmt.Sprintf("Clone: %s", repoName), iconLen)
+		if m.existingClone != nil {
+			displayText = m.fitCreateText("Open existing clone: "+m.existingClone.Basename+m.keyNote("create", "clones again"), iconLen)
+		}
+		if isSelected {
+			result.WriteString(selectedStyle.Render(createNewStyle.Render(displayText)))
+		} else {
+			result.WriteString(createNewStyle.Render(displayText))
+		}
+	} else {
+		result.WriteString(icon("create") + " ")
+		iconLen = lipgloss.Width(icon("create"))
+		if m.query == "" {
+			displayText = "Create new experiment..."
+		} else {
+			displayText = m.fitCreateText(fmt.Sprintf("Create: %s", m.query), iconLen)
+		}
+		if isSelected {
+			result.WriteString(selectedStyle.Render(createNewStyle.Render(displayText)))
+		} else {
+			result.WriteString(createNewStyle.Render(displayText))
+		}
+	}
+
+	// Padding
+	textLen := lipgloss.Width(displayText) + iconLen
+	paddingNeeded := m.width - 2 - textLen // -2 for cursor space
+	if paddingNeeded > 0 {
+		result.WriteString(strings.Repeat(" ", paddingNeeded))
+	}
+
+	return result.String()
+}
+
+// fitCreateText clips the create row's text so the row stays on one line
+func (m model) fitCreateText(text string, iconLen int) string {
+	return truncateWidth(text, m.width-3-iconLen) // cursor and the space after the icon
+}
+
+// cleanDisplayName makes a directory name safe to draw: control characters are
+// escaped, invalid UTF-8 is replaced and leading or trailing spaces are shown
+// as ␣ so they don't vanish
+func cleanDisplayName(name string) string {
+	name = strings.ToValidUTF8(name, "\uFFFD")
+
+	var b strings.Builder
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			fmt.Fprintf(&b, "\\x%02x", r)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	cleaned := b.String()
+
+	trimmed := strings.TrimLeft(cleaned, " ")
+	lead := len(cleaned) - len(trimmed)
+	inner := strings.TrimRight(trimmed, " ")
+	trail := len(trimmed) - len(inner)
+	if lead == 0 && trail == 0 {
+		return cleaned
+	}
+	return strings.Repeat("␣", lead) + inner + strings.Repeat("␣", trail)
+}
+
+// dropLastRune removes text's last character, which may take several bytes
+func dropLastRune(text string) string {
+	_, size := utf8.DecodeLastRuneInString(text)
+	return text[:len(text)-size]
+}
+
+// truncateWidth clips plain (unstyled) text to max terminal columns, ending
+// with an ellipsis when anything was cut. Wide characters count double.
+func truncateWidth(text string, max int) string {
+	if lipgloss.Width(text) <= max {
+		return text
+	}
+	if max <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	width := 0
+	for _, r := range text {
+		w := lipgloss.Width(string(r))
+		if width+w > max-1 {
+			break
+		}
+		b.WriteRune(r)
+		width += w
+	}
+	return b.String() + "…"
+}
+
+func (m model) highlightMatches(text string) string {
+	return m.highlightMatchesIn(text, lipgloss.NewStyle())
+}
+
+// highlightMatchesIn highlights the query's matches in text, rendering the
+// unmatched parts with base
+func (m model) highlightMatchesIn(text string, base lipgloss.Style) string {
+	if m.query == "" {
+		return base.Render(text)
+	}
+
+	if m.matchMode != matchFuzzy {
+		start, end := m.literalMatch(text)
+		if start < 0 || start == end {
+			return base.Render(text)
+		}
+		return base.Render(text[:start]) + matchStyle.Render(text[start:end]) + base.Render(text[end:])
+	}
+
+	// Each search word is matched separately, like in calculateScore
+	textChars := []rune(text)
+	marked := make([]bool, len(textChars))
+	for _, word := range strings.Fields(strings.ToLower(m.query)) {
+		queryChars := []rune(word)
+		queryIdx := 0
+		for pos, char := range textChars {
+			if queryIdx < len(queryChars) && strings.ToLower(string(char)) == string(queryChars[queryIdx]) {
+				marked[pos] = true
+				queryIdx++
+			}
+		}
+	}
+
+	var result strings.Builder
+	for pos, char := range textChars {
+		if marked[pos] {
+			result.WriteString(matchStyle.Render(string(char)))
+		} else {
+			result.WriteString(base.Render(string(char)))
+		}
+	}
+
+	return result.String()
+}
+
+func (m model) formatRelativeTime(t time.Time) string {
+	if m.config != nil && m.config.RelativeTimeStyle == "natural" {
+		return naturalTime(t, time.Now())
+	}
+
+	duration := time.Since(t)
+
+	switch {
+	case duration < 10*time.Second:
+		return "just now"
+	case duration < time.Hour:
+		return fmt.Sprintf("%dm ago", int(duration.Minutes()))
+	case duration < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(duration.Hours()))
+	case duration < 30*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(duration.Hours()/24))
+	case duration < 365*24*time.Hour:
+		return fmt.Sprintf("%dmo ago", int(duration.Hours()/(24*30)))
+	default:
+		return fmt.Sprintf("%dy ago", int(duration.Hours()/(24*365)))
+	}
+}
+
+// ageStyle picks the age coloring bucket for something last used at t
+func ageStyle(t, now time.Time) lipgloss.Style {
+	y, mo, d := now.Date()
+	midnight := time.Date(y, mo, d, 0, 0, 0, 0, now.Location())
+	switch {
+	case !t.Before(midnight):
+		return ageTodayStyle
+	case now.Sub(t) < 7*24*time.Hour:
+		return ageWeekStyle
+	case now.Sub(t) < 30*24*time.Hour:
+		return ageMonthStyle
+	default:
+		return ageOldStyle
+	}
+}
+
+// naturalTime phrases t relative to now ("today 14:05", "yesterday", "last week").
+// Day boundaries follow the local calendar rather than 24-hour periods, so
+// something from 23:50 is "yesterday" at 00:10.
+func naturalTime(t, now time.Time) string {
+	duration := now.Sub(t)
+	if duration < time.Minute {
+		return "just now"
+	}
+	if duration < time.Hour {
+		return fmt.Sprintf("%dm ago", int(duration.Minutes()))
+	}
+
+	t = t.In(now.Location())
+	y1, m1, d1 := t.Date()
+	y2, m2, d2 := now.Date()
+	// Compare midnights in UTC so DST changes don't skew the day count
+	days := int(time.Date(y2, m2, d2, 0, 0, 0, 0, time.UTC).Sub(time.Date(y1, m1, d1, 0, 0, 0, 0, time.UTC)).Hours() / 24)
+	months := (y2-y1)*12 + int(m2) - int(m1)
+	if d2 < d1 {
+		// Only whole months count
+		months--
+	}
+
+	switch {
+	case days == 0:
+		return "today " + t.Format("15:04")
+	case days == 1:
+		return "yesterday"
+	case days < 7:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 14:
+		return "last week"
+	case days < 31:
+		return fmt.Sprintf("%d weeks ago", days/7)
+	case months < 2:
+		return "last month"
+	case months < 12:
+		return fmt.Sprintf("%d months ago", months)
+	case months < 24:
+		return "last year"
+	default:
+		return fmt.Sprintf("%d years ago", months/12)
+	}
+}
+
+func handleDirectClone(url string, config *Config) {
+	// Validate it's a repository URL
+	isURL, cloneURL := isRepoURL(url)
+	if !isURL {
+		fmt.Fprintf(os.Stderr, "Error: Not a GitHub, GitLab, Bitbucket or Codeberg repository URL: %s\n", url)
+		os.Exit(1)
+	}
+
+	// Get base path
+	basePath, config := requireBasePath(config)
+
+	// Enter an existing clone of the same repository rather than making another
+	if !config.ForceClone {
+		m := model{basePath: basePath, roots: getRoots(config, basePath), config: config}
+		m.loadTries()
+		if existing, ok := findClone(m.tries, cloneURL); ok {
+			if err := os.Chdir(existing.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: couldn't change directory: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(os.Stderr, "\n%s Already cloned as %s, entering it (--force clones it again)\n\n", icon("enter"), existing.Basename)
+			touchPath(existing.Path, config, false)
+			exitAfterShell(launchShell(existing.Path, config), config)
+			return
+		}
+	}
+
+	// Perform the clone
+	fullPath, err := performClone(cloneURL, basePath, config, isInteractive())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Change to the directory
+	if err := os.Chdir(fullPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: couldn't change directory: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Launch a new shell
+	fmt.Fprintf(os.Stderr, "\n%s Successfully cloned and entering %s\n\n", icon("create"), filepath.Base(fullPath))
+
+	exitAfterShell(launchShell(fullPath, config), config)
+}
+
+// ensureChild guards destructive operations: target must be a direct child
+// of root, and never root itself
+func ensureChild(root, target string) error {
+	root = filepath.Clean(root)
+	target = filepath.Clean(target)
+	if root == "" || !filepath.IsAbs(target) {
+		return fmt.Errorf("refusing to operate on %s: not an absolute path", target)
+	}
+	if target == root {
+		return fmt.Errorf("refusing to operate on the base path itself: %s", target)
+	}
+	if filepath.Dir(target) != root {
+		return fmt.Errorf("refusing to operate on %s: not directly inside %s", target, root)
+	}
+	return nil
+}
+
+// isUnder reports whether target is root or lies somewhere inside it
+func isUnder(root, target string) bool {
+	rel, err := filepath.Rel(filepath.Clean(root), filepath.Clean(target))
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// trashRecord remembers where a deleted experiment was moved, for undo
+type trashRecord struct {
+	path      string // Where it was
+	trashPath string // Where it is now
+}
+
+// validateTrashDir rejects a trash_dir that is, holds or lies inside one of
+// roots: emptying it would remove live experiments, and the trash view
+// would list them as deleted
+func validateTrashDir(trash string, roots []string) error {
+	if trash == "" {
+		return nil
+	}
+	for _, root := range roots {
+		if root == "" {
+			continue
+		}
+		if isUnder(root, trash) || isUnder(trash, root) {
+			return fmt.Errorf("invalid trash_dir %s: it overlaps the experiments directory %s; pick a directory outside it", trash, root)
+		}
+	}
+	return nil
+}
+
+// trashDir returns where experiments deleted from root are moved
+func trashDir(root string, config *Config) string {
+	if config != nil && config.TrashDir != "" {
+		return config.TrashDir
+	}
+	return filepath.Join(root, trashDirName)
+}
+
+// trashEntry moves entry into the trash as "<timestamp>-<name>", after
+// checking it is a direct child of its root, and returns its new path
+func trashEntry(entry tryEntry, config *Config) (string, error) {
+	if err := ensureChild(entry.Root, entry.Path); err != nil {
+		return "", err
+	}
+	dir := trashDir(entry.Root, config)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	name := time.Now().Format("20060102-150405") + "-" + entry.Basename
+	target := filepath.Join(dir, name)
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s-%d", name, n))
+	}
+	if err := os.Rename(entry.Path, target); err != nil {
+		return "", err
+	}
+	if config != nil && config.TrashDir != "" {
+		// A shared trash can't tell which root an entry came from by itself.
+		// Without the note a restore falls back to the primary root.
+		os.WriteFile(target+trashOriginSuffix, []byte(entry.Path+"\n"), 0644)
+	}
+	return target, nil
+}
+
+// trashOriginSuffix names the note next to an entry in a shared trash_dir
+// that records where it was deleted from
+const trashOriginSuffix = ".try-origin"
+
+// trashOrigin returns the path a trashed entry was deleted from, according
+// to its origin note, if that is directly inside one of roots
+func trashOrigin(trashPath string, roots []string) (string, bool) {
+	data, err := os.ReadFile(trashPath + trashOriginSuffix)
+	if err != nil {
+		return "", false
+	}
+	origin := filepath.Clean(strings.TrimSpace(string(data)))
+	for _, root := range roots {
+		if ensureChild(root, origin) == nil {
+			return origin, true
+		}
+	}
+	return "", false
+}
+
+// isOriginNote reports whether name, one of the entries of a trash
+// directory, is the origin note of another of them rather than a trashed file
+func isOriginNote(name string, entries []os.DirEntry) bool {
+	if !strings.HasSuffix(name, trashOriginSuffix) {
+		return false
+	}
+	trashed := strings.TrimSuffix(name, trashOriginSuffix)
+	for _, entry := range entries {
+		if entry.Name() == trashed {
+			return true
+		}
+	}
+	return false
+}
+
+// markedEntries returns the marked entries in list order
+func (m model) markedEntries() []tryEntry {
+	var entries []tryEntry
+	for _, try := range m.filteredTries {
+		if m.marked[try.Path] {
+			entries = append(entries, try)
+		}
+	}
+	return entries
+}
+
+// deletePhrase is what has to be typed to confirm the pending delete: the
+// entry's name, or "delete N" for a batch
+func (m model) deletePhrase() string {
+	if len(m.deleteBatch) > 0 {
+		return fmt.Sprintf("delete %d", len(m.deleteBatch))
+	}
+	return m.deleteTarget.Name
+}
+
+// deleteEntries moves entries to the trash, stopping at the first failure,
+// and reloads the list with the cursor kept on the entry it was on if that
+// one survived
+func (m *model) deleteEntries(entries []tryEntry) {
+	var selected string
+	if m.cursor < len(m.filteredTries) {
+		selected = m.filteredTries[m.cursor].Path
+	}
+
+	deleted := 0
+	var failure error
+	for _, entry := range entries {
+		// Move it to the trash, but never anything outside the experiments directory
+		trashPath, err := trashEntry(entry, m.config)
+		if err != nil {
+			failure = fmt.Errorf("%s: %w", entry.Basename, err)
+			break
+		}
+		m.trashed = append(m.trashed, trashRecord{path: entry.Path, trashPath: trashPath})
+		m.actions = append(m.actions, "deleted "+entry.Basename)
+		deleted++
+	}
+	switch {
+	case failure != nil && deleted == 0:
+		m.statusMsg = fmt.Sprintf("Delete failed: %v", failure)
+	case failure != nil:
+		m.statusMsg = fmt.Sprintf("Deleted %d of %d, then failed on %v", deleted, len(entries), failure)
+	case deleted == 1:
+		m.statusMsg = "Moved " + entries[0].Basename + " to the trash" + m.keyNote("undo", "to undo")
+	default:
+		m.statusMsg = fmt.Sprintf("Moved %d directories to the trash", deleted) + m.keyNote("undo", "restores them one at a time")
+	}
+	if deleted == 0 {
+		return
+	}
+
+	// Reload directories and keep the cursor in bounds
+	m.loadTries()
+	m.filterTries()
+	cursor := -1
+	for i, try := range m.filteredTries {
+		if try.Path == selected {
+			cursor = i
+		}
+	}
+	if cursor < 0 {
+		cursor = min(m.cursor, max(len(m.filteredTries)-1, 0))
+	}
+	m.cursor = cursor
+	m.adjustScroll()
+}
+
+// trashPrefix matches the "<timestamp>-" trashEntry puts before a name
+var trashPrefix = regexp.MustCompile(`^\d{8}-\d{6}-`)
+
+// trashedTries lists what's in the trash of each root, as entries named as
+// they were before deletion and rooted where restoreEntry puts them back.
+// In a shared trash_dir that's the root in the entry's origin note, or the
+// first root without one.
+func (m model) trashedTries() []tryEntry {
+	roots := m.roots
+	if len(roots) == 0 {
+		roots = []string{m.basePath}
+	}
+	var tries []tryEntry
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		dir := trashDir(root, m.config)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			isFile := entry.Type().IsRegular()
+			if (!entry.IsDir() && !isFile) || isOriginNote(entry.Name(), entries) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			name := trashPrefix.ReplaceAllString(entry.Name(), "")
+			root := root
+			if origin, ok := trashOrigin(path, roots); ok {
+				root = filepath.Dir(origin)
+				name = filepath.Base(origin)
+			}
+			_, gitErr := os.Stat(filepath.Join(path, ".git"))
+			tries = append(tries, tryEntry{
+				Name:     name,
+				Basename: name,
+				Path:     path,
+				IsFile:   isFile,
+				IsRepo:   !isFile && gitErr == nil,
+				Root:     root,
+				CTime:    info.ModTime(),
+				MTime:    info.ModTime(),
+				Trashed:  true,
+			})
+		}
+	}
+	return tries
+}
+
+// toggleTrash shows or hides the trash's contents in the list
+func (m *model) toggleTrash() {
+	m.showTrash = !m.showTrash
+	m.loadTries()
+	m.filterTries()
+	m.cursor = 0
+	m.scrollOffset = 0
+	if !m.showTrash {
+		m.statusMsg = "Hiding the trash"
+		return
+	}
+	count := 0
+	for _, try := range m.tries {
+		if try.Trashed {
+			count++
+		}
+	}
+	if count == 0 {
+		m.statusMsg = "The trash is empty"
+	} else {
+		m.statusMsg = fmt.Sprintf("Showing %d from the trash, marked %s", count, icon("trash")) + m.keyNote("select", "restores one")
+	}
+}
+
+// restoreEntry moves a trashed entry back into its root under its old name,
+// refusing to replace anything there, and returns it as listed from there
+func (m *model) restoreEntry(entry tryEntry) (tryEntry, error) {
+	target := filepath.Join(entry.Root, entry.Name)
+	if err := ensureChild(entry.Root, target); err != nil {
+		return entry, err
+	}
+	if _, err := os.Lstat(target); err == nil {
+		return entry, fmt.Errorf("%s already exists", target)
+	}
+	if err := os.Rename(entry.Path, target); err != nil {
+		return entry, err
+	}
+	os.Remove(entry.Path + trashOriginSuffix)
+	// It's no longer this session's to undo
+	for i, record := range m.trashed {
+		if record.trashPath == entry.Path {
+			m.trashed = append(m.trashed[:i], m.trashed[i+1:]...)
+			break
+		}
+	}
+	m.actions = append(m.actions, "restored "+entry.Name)
+
+	entry.Path, entry.Trashed = target, false
+	return entry, nil
+}
+
+// undoDelete moves the experiment deleted last this session back from the
+// trash, unless something has taken its place since
+func (m *model) undoDelete() {
+	if len(m.trashed) == 0 {
+		// Deletions from earlier runs aren't tracked, they're only in the trash
+		m.statusMsg = "Nothing to undo this session; earlier deletions stay in the trash" + m.keyNote("trash", "shows it")
+		return
+	}
+	last := m.trashed[len(m.trashed)-1]
+	name := filepath.Base(last.path)
+	if _, err := os.Lstat(last.path); err == nil {
+		m.statusMsg = fmt.Sprintf("Can't restore %s: something else is there now", name)
+		return
+	}
+	if err := os.Rename(last.trashPath, last.path); err != nil {
+		m.statusMsg = fmt.Sprintf("Couldn't restore %s: %v", name, err)
+		return
+	}
+	os.Remove(last.trashPath + trashOriginSuffix)
+	m.trashed = m.trashed[:len(m.trashed)-1]
+	m.actions = append(m.actions, "restored "+name)
+	m.statusMsg = "Restored " + name
+
+	m.loadTries()
+	m.filterTries()
+	for i, try := range m.filteredTries {
+		if try.Path == last.path {
+			m.cursor = i
+		}
+	}
+	m.adjustScroll()
+}
+
+// handleEmptyTrash permanently removes everything in the trash of each root
+func handleEmptyTrash(config *Config) {
+	basePath := getDefaultPath(config)
+	if basePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no experiments directory configured yet")
+		os.Exit(1)
+	}
+
+	removed, failed := 0, false
+	var size int64
+	seen := make(map[string]bool)
+	for _, root := range getRoots(config, basePath) {
+		dir := trashDir(root, config)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if isOriginNote(entry.Name(), entries) {
+				// Removed along with its entry
+				continue
+			}
+			entrySize := measureDir(path)
+			if err := removeChild(dir, path); err != nil {
+				fmt.Fprintf(os.Stderr, "Error removing %s: %v\n", path, err)
+				failed = true
+				continue
+			}
+			os.Remove(path + trashOriginSuffix)
+			removed++
+			size += entrySize
+		}
+	}
+
+	if removed == 0 && !failed {
+		fmt.Fprintln(os.Stderr, "The trash is already empty")
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s Emptied the trash: %d removed (%s)\n", icon("success"), removed, formatSize(size))
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// parseAgeDays reads an age like "90d", "12w" or "1y" (a bare number is
+// days) as a number of days
+func parseAgeDays(age string) (int, error) {
+	unit := 1
+	number := age
+	switch {
+	case strings.HasSuffix(age, "d"):
+		number = strings.TrimSuffix(age, "d")
+	case strings.HasSuffix(age, "w"):
+		number, unit = strings.TrimSuffix(age, "w"), 7
+	case strings.HasSuffix(age, "y"):
+		number, unit = strings.TrimSuffix(age, "y"), 365
+	}
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("takes a positive number of days, weeks or years, e.g. 90d, 12w or 1y, got %q", age)
+	}
+	return n * unit, nil
+}
+
+// handlePrune moves experiments with no files, and with prune_after_days set
+// those unchanged for longer, to the trash after listing them and asking
+// once. Pinned experiments are always kept.
+func handlePrune(config *Config) {
+	basePath := getDefaultPath(config)
+	if basePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no experiments directory configured yet")
+		os.Exit(1)
+	}
+
+	m := model{
+		basePath: basePath,
+		roots:    getRoots(config, basePath),
+		config:   config,
+	}
+	m.loadTries()
+
+	var cutoff time.Time
+	if config.PruneAfterDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -config.PruneAfterDays)
+	}
+	var candidates []tryEntry
+	var reasons []string
+	for _, entry := range m.tries {
+		if m.isPinned(entry) {
+			continue
+		}
+		empty := false
+		if !entry.IsFile {
+			// Something unreadable inside would look empty and get trashed
+			var err error
+			if empty, err = isEmptyDir(entry.Path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipping %s: %v\n", entry.Basename, err)
+				continue
+			}
+		}
+		switch {
+		case empty:
+			reasons = append(reasons, "empty")
+		case !cutoff.IsZero() && entry.MTime.Before(cutoff):
+			reasons = append(reasons, "changed "+m.formatRelativeTime(entry.MTime))
+		default:
+			continue
+		}
+		candidates = append(candidates, entry)
+	}
+
+	if len(candidates) == 0 {
+		if cutoff.IsZero() {
+			fmt.Fprintln(os.Stderr, "No empty experiments to prune")
+		} else {
+			fmt.Fprintf(os.Stderr, "No empty experiments or ones unchanged for %d days to prune\n", config.PruneAfterDays)
+		}
+		return
+	}
+
+	width := 0
+	for _, entry := range candidates {
+		width = max(width, len(entry.Basename))
+	}
+	for i, entry := range candidates {
+		fmt.Fprintf(os.Stderr, "  %-*s  %s\n", width, entry.Basename, dimStyle.Render(reasons[i]))
+	}
+
+	noun := "experiments"
+	if len(candidates) == 1 {
+		noun = "experiment"
+	}
+	if !assumeYes {
+		fmt.Fprintf(os.Stderr, "\nMove %d %s to the trash? [y/N] ", len(candidates), noun)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+		default:
+			fmt.Fprintln(os.Stderr, "Nothing pruned")
+			return
+		}
+	}
+
+	moved, failed := 0, false
+	for _, entry := range candidates {
+		if _, err := trashEntry(entry, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error moving %s to the trash: %v\n", entry.Basename, err)
+			failed = true
+			continue
+		}
+		moved++
+	}
+	if moved != 1 {
+		noun = "experiments"
+	}
+	fmt.Fprintf(os.Stderr, "%s Moved %d %s to the trash (try --empty-trash removes them for good)\n", icon("success"), moved, noun)
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// removeChild removes target after checking it is a direct child of root
+func removeChild(root, target string) error {
+	if err := ensureChild(root, target); err != nil {
+		return err
+	}
+	return os.RemoveAll(target)
+}
+
+// Built-in .gitignore templates, selectable by name via --gitignore or default_gitignore
+var gitignoreTemplates = map[string]string{
+	"go":     "# Binaries\n*.exe\n*.test\n*.out\n/bin/\n\n# Dependencies\n/vendor/\n\n# Coverage\ncoverage.*\n",
+	"node":   "node_modules/\ndist/\nbuild/\n.env\nnpm-debug.log*\nyarn-error.log*\n.DS_Store\n",
+	"python": "__pycache__/\n*.py[cod]\n.venv/\nvenv/\n.env\n*.egg-info/\n.pytest_cache/\n.ipynb_checkpoints/\n",
+	"rust":   "/target/\n**/*.rs.bk\n",
+}
+
+// gitignoreLangs lists the built-in template names in a stable order
+var gitignoreLangs = []string{"go", "node", "python", "rust"}
+
+// gitignoreContent resolves a gitignore spec: a built-in language name,
+// inline content (anything spanning several lines) or a template file path
+func gitignoreContent(spec string) (string, error) {
+	if content, ok := gitignoreTemplates[strings.ToLower(spec)]; ok {
+		return content, nil
+	}
+	if strings.Contains(spec, "\n") {
+		if !strings.HasSuffix(spec, "\n") {
+			spec += "\n"
+		}
+		return spec, nil
+	}
+
+	path, err := sanitizePath(spec)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("no built-in template %q and can't read it as a file: %w", spec, err)
+	}
+	return string(data), nil
+}
+
+// scaffoldExperiment fills in a freshly created experiment directory.
+// Failures are reported but never stop the experiment from being entered.
+func scaffoldExperiment(path string, config *Config) {
+	if config == nil {
+		return
+	}
+	if config.InitGit {
+		initGitRepo(path)
+	}
+	if config.TemplateDir != "" {
+		if err := copyTemplate(config.TemplateDir, path); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: couldn't copy template: %v\n", err)
+		}
+	}
+	if config.DefaultGitignore == "" {
+		return
+	}
+
+	gitignorePath := filepath.Join(path, ".gitignore")
+	if _, err := os.Stat(gitignorePath); err == nil {
+		return
+	}
+	content, err := gitignoreContent(config.DefaultGitignore)
+	if err == nil {
+		err = os.WriteFile(gitignorePath, []byte(content), 0644)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't write .gitignore: %v\n", err)
+	}
+}
+
+// copyTemplate copies the contents of the template directory into dst,
+// keeping its structure and file modes. Files already in dst are left alone,
+// and a .git directory in the template isn't copied.
+func copyTemplate(template, dst string) error {
+	info, err := os.Stat(template)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", template)
+	}
+	return filepath.WalkDir(template, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(template, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		target := filepath.Join(dst, rel)
+		if _, err := os.Lstat(target); err == nil {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case d.Type()&fs.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case d.Type().IsRegular():
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(target, data, info.Mode().Perm())
+		}
+		return nil
+	})
+}
 
-			case "backspace":
-				if len(m.newName) > 0 {
-					m.newName = m.newName[:len(m.newName)-1]
-				}
+// initGitRepo runs git init in path. Without git on the PATH there's nothing
+// to do, so that is skipped silently.
+func initGitRepo(path string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return
+	}
+	cmd := exec.Command("git", "init", "--quiet", path)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: git init failed: %v\n%s", err, output)
+	}
+}
 
-			default:
-				// Handle character input
-				if len(msg.String()) == 1 {
-					m.newName += msg.String()
-				}
+// countFiles counts regular files under dir, stopping once limit is exceeded
+func countFiles(dir string, limit int) int {
+	count := 0
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			count++
+			if count > limit {
+				return filepath.SkipAll
 			}
-			return m, nil
 		}
+		return nil
+	})
+	return count
+}
 
-		// Handle delete confirmation mode
-		if m.confirmDelete && m.deleteTarget != nil {
-			switch msg.String() {
-			case "y", "Y":
-				// Perform deletion
-				if err := os.RemoveAll(m.deleteTarget.Path); err != nil {
-					// Could add error handling here, but for now just reset
-					m.confirmDelete = false
-					m.deleteTarget = nil
-					return m, nil
-				}
-				// Reload directories and reset state
-				m.loadTries()
-				m.filterTries()
-				m.confirmDelete = false
-				m.deleteTarget = nil
-				// Adjust cursor if it's out of bounds
-				if m.cursor >= len(m.filteredTries) {
-					m.cursor = len(m.filteredTries) - 1
-					if m.cursor < 0 {
-						m.cursor = 0
-					}
-				}
-			default:
-				// Cancel deletion on any other key
-				m.confirmDelete = false
-				m.deleteTarget = nil
-			}
-			return m, nil
+// isEmptyDir reports whether dir holds no files at any depth. Unlike
+// countFiles it fails when part of the tree can't be read.
+func isEmptyDir(dir string) (bool, error) {
+	empty := true
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
 		}
+		if !d.IsDir() {
+			empty = false
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return empty && err == nil, err
+}
 
-		// Normal mode
-		switch msg.String() {
-		case "ctrl+c", "esc", "q":
-			m.quitting = true
-			return m, tea.Quit
+// handleScratch creates a throwaway experiment, launches a shell in it and
+// removes it again on exit unless it has accumulated real work
+func handleScratch(config *Config) {
+	basePath, config := requireBasePath(config)
 
-		case "ctrl+n":
-			// Quick create new experiment or clone
-			if m.searchTerm != "" {
-				// Check if it's a GitHub URL
-				isGH, cloneURL := isGitHubURL(m.searchTerm)
-				if isGH {
-					// Clone repository
-					repoName := extractRepoName(cloneURL)
-					datePrefix := time.Now().Format("2006-01-02")
-					finalName := fmt.Sprintf("%s-%s", datePrefix, repoName)
-					fullPath := filepath.Join(m.basePath, finalName)
-					m.selected = &selection{
-						Type:     "clone",
-						Path:     fullPath,
-						CloneURL: cloneURL,
-					}
-					m.quitting = true
-					return m, tea.Quit
-				} else {
-					// Regular create
-					datePrefix := time.Now().Format("2006-01-02")
-					finalName := fmt.Sprintf("%s-%s", datePrefix, strings.ReplaceAll(m.searchTerm, " ", "-"))
-					fullPath := filepath.Join(m.basePath, finalName)
-					m.selected = &selection{
-						Type: "mkdir",
-						Path: fullPath,
-					}
-					m.quitting = true
-					return m, tea.Quit
-				}
-			} else {
-				// Enter input mode for new name
-				m.inputMode = true
-				m.newName = ""
-			}
+	name := time.Now().Format("2006-01-02-scratch-150405")
+	scratchPath := filepath.Join(basePath, scratchDirName, name)
+	if err := os.MkdirAll(scratchPath, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating scratch directory: %v\n", err)
+		os.Exit(1)
+	}
 
-		case "ctrl+d", "delete":
-			// Delete directory with confirmation
-			if m.cursor < len(m.filteredTries) {
-				m.confirmDelete = true
-				entry := m.filteredTries[m.cursor]
-				m.deleteTarget = &entry
-			}
+	fmt.Fprintf(os.Stderr, "\n%s Entering scratch experiment %s\n", icon("scratch"), name)
+	fmt.Fprintf(os.Stderr, "%s\n\n", dimStyle.Render(fmt.Sprintf("It will be removed when you exit the shell unless it holds more than %d files", scratchKeepThreshold)))
 
-		case "enter":
-			if m.cursor < len(m.filteredTries) {
-				// Select existing directory
-				m.selected = &selection{
-					Type: "cd",
-					Path: m.filteredTries[m.cursor].Path,
-				}
-				m.quitting = true
-				return m, tea.Quit
-			} else if m.cursor == len(m.filteredTries) {
-				// Create new directory or clone repository
-				if m.searchTerm != "" {
-					// Check if it's a GitHub URL
-					isGH, cloneURL := isGitHubURL(m.searchTerm)
-					if isGH {
-						// Clone repository
-						repoName := extractRepoName(cloneURL)
-						datePrefix := time.Now().Format("2006-01-02")
-						finalName := fmt.Sprintf("%s-%s", datePrefix, repoName)
-						fullPath := filepath.Join(m.basePath, finalName)
-						m.selected = &selection{
-							Type:     "clone",
-							Path:     fullPath,
-							CloneURL: cloneURL,
-						}
-						m.quitting = true
-						return m, tea.Quit
-					} else {
-						// Regular create
-						datePrefix := time.Now().Format("2006-01-02")
-						finalName := fmt.Sprintf("%s-%s", datePrefix, strings.ReplaceAll(m.searchTerm, " ", "-"))
-						fullPath := filepath.Join(m.basePath, finalName)
-						m.selected = &selection{
-							Type: "mkdir",
-							Path: fullPath,
-						}
-						m.quitting = true
-						return m, tea.Quit
-					}
-				} else {
-					// Enter input mode for new name
-					m.inputMode = true
-					m.newName = ""
-				}
-			}
+	shellErr := launchShell(scratchPath, config)
 
-		case "up", "ctrl+p", "ctrl+k":
-			if m.cursor > 0 {
-				m.cursor--
-				m.adjustScroll()
-			}
+	if files := countFiles(scratchPath, scratchKeepThreshold); files > scratchKeepThreshold {
+		// Too much to throw away, promote it to a regular experiment
+		keptName := nextFreeName(basePath, name)
+		keptPath := filepath.Join(basePath, keptName)
+		if err := os.Rename(scratchPath, keptPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: couldn't move scratch experiment out of %s: %v\n", scratchPath, err)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s Kept scratch experiment as %s\n", icon("dir"), keptName)
+		}
+	} else if err := removeChild(filepath.Join(basePath, scratchDirName), scratchPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: couldn't remove scratch directory %s: %v\n", scratchPath, err)
+	} else {
+		fmt.Fprintf(os.Stderr, "%s Removed scratch experiment %s\n", icon("scratch"), name)
+	}
 
-		case "down", "ctrl+j":
-			totalItems := len(m.filteredTries) + 1
-			if m.cursor < totalItems-1 {
-				m.cursor++
-				m.adjustScroll()
-			}
+	exitAfterShell(shellErr, config)
+}
 
-		case "backspace":
-			if len(m.searchTerm) > 0 {
-				m.searchTerm = m.searchTerm[:len(m.searchTerm)-1]
-				m.filterTries()
-				m.cursor = 0
-				m.scrollOffset = 0
-			}
+// Limits on what --grep reads, so a huge checkout can't stall the search
+const (
+	grepMaxFileSize = 1 << 20 // Bigger files are skipped
+	grepMaxFiles    = 5000    // Files read per experiment
+)
 
-		case "ctrl+u":
-			// Clear search
-			m.searchTerm = ""
-			m.filterTries()
-			m.cursor = 0
-			m.scrollOffset = 0
+// grepSkipDirs are never searched: they hold history or dependencies, not your work
+var grepSkipDirs = map[string]bool{".git": true, "node_modules": true}
+
+// grepTries keeps the entries that have a file matching pattern, setting
+// their Hits and HitFile. Entries are searched in parallel.
+func grepTries(tries []tryEntry, pattern *regexp.Regexp) []tryEntry {
+	results := make([]tryEntry, len(tries))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, runtime.NumCPU())
+	for i := range tries {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			results[i] = tries[i]
+			results[i].Hits, results[i].HitFile = grepEntry(tries[i].Path, pattern)
+		}(i)
+	}
+	wg.Wait()
 
-		default:
-			// Handle character input for search (including paste)
-			switch msg.Type {
-			case tea.KeyRunes:
-				// This handles both single chars and pasted content
-				input := string(msg.Runes)
-				if isValidSearchInput(input) {
-					m.searchTerm += input
-					m.filterTries()
-					m.cursor = 0
-					m.scrollOffset = 0
-				}
-			}
+	var matched []tryEntry
+	for _, try := range results {
+		if try.Hits > 0 {
+			matched = append(matched, try)
 		}
 	}
+	return matched
+}
 
-	return m, nil
+// grepEntry counts the lines matching pattern in the text files under path
+// and returns the total and the file, relative to path, with the most
+func grepEntry(path string, pattern *regexp.Regexp) (int, string) {
+	total, best, bestFile := 0, 0, ""
+	files := 0
+	filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if grepSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if files++; files > grepMaxFiles {
+			return filepath.SkipAll
+		}
+		if info, err := d.Info(); err != nil || info.Size() > grepMaxFileSize {
+			return nil
+		}
+		data, err := os.ReadFile(file)
+		if err != nil || bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+			// Unreadable or binary
+			return nil
+		}
+		count := 0
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if pattern.Match(line) {
+				count++
+			}
+		}
+		total += count
+		if count > best {
+			best = count
+			if bestFile, err = filepath.Rel(path, file); err != nil || bestFile == "." {
+				bestFile = d.Name()
+			}
+		}
+		return nil
+	})
+	return total, bestFile
 }
 
-func (m *model) adjustScroll() {
-	maxVisible := m.height - 10
-	if maxVisible < 3 {
-		maxVisible = 3
-	}
+// handleNew creates a dated experiment called name without the selector,
+// named like Ctrl+N would and given the next free -N suffix if the name is
+// taken today, then enters it
+func handleNew(name string, config *Config, selectOnly bool) {
+	basePath, config := requireBasePath(config)
 
-	if m.cursor < m.scrollOffset {
-		m.scrollOffset = m.cursor
-	} else if m.cursor >= m.scrollOffset+maxVisible {
-		m.scrollOffset = m.cursor - maxVisible + 1
+	dirName := nextFreeName(basePath, datedName(name, config))
+	if err := validateExperimentName(dirName); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: can't create %q: %v\n", dirName, err)
+		os.Exit(1)
 	}
+	handleSelection(&selection{Type: "mkdir", Path: filepath.Join(basePath, dirName)}, basePath, config, selectOnly)
 }
 
-func (m model) View() string {
-	if m.quitting {
-		return ""
+// handleLast enters the most recently modified experiment, for --last
+func handleLast(config *Config, selectOnly, run bool) {
+	basePath, config := requireBasePath(config)
+
+	m := model{
+		basePath: basePath,
+		roots:    getRoots(config, basePath),
+		config:   config,
+	}
+	m.loadTries()
+	if len(m.tries) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: there are no experiments yet")
+		os.Exit(1)
 	}
 
-	var b strings.Builder
+	last := m.tries[0]
+	for _, entry := range m.tries[1:] {
+		if entry.MTime.After(last.MTime) {
+			last = entry
+		}
+	}
+	sel := &selection{Type: "cd", Path: last.Path}
+	if last.IsFile {
+		sel.Type = "edit"
+	} else if run {
+		sel.Type = "run"
+	}
+	handleSelection(sel, basePath, config, selectOnly)
+}
 
-	// Title
-	b.WriteString(titleStyle.Render("📁 Try - Quick Experiment Directories"))
-	b.WriteString("\n")
+// handleToday finds or creates today's dated experiment and enters it
+func handleToday(config *Config, selectOnly bool) {
+	basePath, config := requireBasePath(config)
 
-	// Handle delete confirmation mode
-	if m.confirmDelete && m.deleteTarget != nil {
-		b.WriteString("\n")
-		b.WriteString(dangerStyle.Render("⚠️  Delete Directory"))
-		b.WriteString("\n\n")
-		b.WriteString("Are you sure you want to delete this directory?\n\n")
-		b.WriteString(warningStyle.Render("  " + m.deleteTarget.Name))
-		b.WriteString("\n")
-		b.WriteString(dimStyle.Render("  " + m.deleteTarget.Path))
-		b.WriteString("\n\n")
-		b.WriteString(dangerStyle.Render("This action cannot be undone!"))
-		b.WriteString("\n\n")
-		b.WriteString(helpStyle.Render("Press 'y' to confirm, any other key to cancel"))
-		return b.String()
+	name := config.TodayName
+	if name == "" {
+		name = defaultTodayName
 	}
+	path := filepath.Join(basePath, datedName(name, config))
 
-	// Handle input mode for new directory
-	if m.inputMode {
-		b.WriteString("\n")
-		b.WriteString(promptStyle.Render("New directory name:"))
-		b.WriteString("\n")
-		datePrefix := time.Now().Format("2006-01-02")
-		b.WriteString(dimStyle.Render(datePrefix + "-"))
-		b.WriteString(searchInputStyle.Render(m.newName))
-		b.WriteString("\n\n")
-		b.WriteString(helpStyle.Render("Enter: Create  ESC: Cancel"))
-		return b.String()
+	_, statErr := os.Stat(path)
+	created := os.IsNotExist(statErr)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
+		os.Exit(1)
 	}
 
-	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width-1)))
-	b.WriteString("\n")
+	touchPath(path, config, selectOnly)
 
-	// Search input
-	b.WriteString(searchStyle.Render("Search: "))
-	b.WriteString(searchInputStyle.Render(m.searchTerm))
-	if m.searchTerm == "" {
-		b.WriteString(dimStyle.Render(" (type to filter)"))
+	if selectOnly {
+		action := "cd"
+		if created {
+			action = "mkdir"
+		}
+		printSelected(&selection{Type: action, Path: path})
+		return
 	}
-	b.WriteString("\n")
-	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width-1)))
-	b.WriteString("\n")
 
-	// Calculate visible window (accounting for extra help lines and separators)
-	maxVisible := m.height - 10
-	if maxVisible < 3 {
-		maxVisible = 3
+	if created {
+		fmt.Fprintf(os.Stderr, "\n%s Created and entering %s\n\n", icon("create"), filepath.Base(path))
+	} else {
+		fmt.Fprintf(os.Stderr, "\n%s Entering today's experiment %s\n\n", icon("today"), filepath.Base(path))
 	}
-	totalItems := len(m.filteredTries) + 1
 
-	// Display items
-	visibleEnd := m.scrollOffset + maxVisible
-	if visibleEnd > totalItems {
-		visibleEnd = totalItems
+	exitAfterShell(launchShell(path, config), config)
+}
+
+// enterBasePath opens a shell in the experiments directory itself, for
+// managing experiments in bulk
+func enterBasePath(basePath string, config *Config, selectOnly bool) {
+	if selectOnly {
+		printSelected(&selection{Type: "root", Path: basePath})
+		return
 	}
 
-	for idx := m.scrollOffset; idx < visibleEnd; idx++ {
-		// Add blank line before "Create new"
-		if idx == len(m.filteredTries) && len(m.filteredTries) > 0 {
-			b.WriteString("\n")
-		}
+	fmt.Fprintf(os.Stderr, "\n%s Entering experiments directory %s\n\n", icon("dir"), basePath)
 
-		// Cursor
-		isSelected := idx == m.cursor
-		if isSelected {
-			b.WriteString(cursorStyle.Render("→ "))
-		} else {
-			b.WriteString("  ")
+	exitAfterShell(launchShell(basePath, config), config)
+}
+
+// handleInit writes the given base path (and optional shell) to the config
+// file without any prompts, e.g. `try init ~/code/tries --shell fish`
+func handleInit(args []string) {
+	path, shell := "", ""
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; arg {
+		case "--shell":
+			if i+1 >= len(args) {
+				fmt.Fprintln(os.Stderr, "Error: --shell requires a shell argument")
+				os.Exit(1)
+			}
+			shell = args[i+1]
+			i++
+		default:
+			if path != "" || strings.HasPrefix(arg, "-") {
+				fmt.Fprintf(os.Stderr, "Error: unexpected argument to init: %s\n", arg)
+				os.Exit(1)
+			}
+			path = arg
 		}
+	}
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "Usage: try init <path> [--shell <shell>]")
+		os.Exit(1)
+	}
 
-		// Display entry
-		if idx < len(m.filteredTries) {
-			entry := m.filteredTries[idx]
-			line := m.formatEntry(entry, isSelected)
-			b.WriteString(line)
-		} else {
-			// Create new option
-			line := m.formatCreateNew(isSelected)
-			b.WriteString(line)
+	absPath, err := sanitizePath(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid path: %v\n", err)
+		os.Exit(1)
+	}
+
+	shellPath := ""
+	if shell != "" {
+		shellPath, err = resolveShellPath(shell)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
-		b.WriteString("\n")
 	}
 
-	// Scroll indicator
-	if totalItems > maxVisible {
-		b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width-1)))
-		b.WriteString("\n")
-		b.WriteString(dimStyle.Render(fmt.Sprintf("[%d-%d/%d]", m.scrollOffset+1, visibleEnd, totalItems)))
-		b.WriteString("\n")
+	if err := os.MkdirAll(absPath, 0755); err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating directory %s: %v\n", absPath, err)
+		os.Exit(1)
 	}
 
-	b.WriteString(separatorStyle.Render(strings.Repeat("─", m.width-1)))
-	b.WriteString("\n")
-	// Navigation hints
-	b.WriteString(helpStyle.Render("↑↓/Ctrl+j,k: Navigate Enter: Select Ctrl+N: Quick new Ctrl+D: Delete"))
-	b.WriteString("\n")
-	// Action hints
-	b.WriteString(helpStyle.Render("ESC/q: Quit"))
+	// Start from the stored config (not env overrides) so other settings survive
+	config, err := updateConfig(func(c *Config) {
+		c.Path = absPath
+		if shellPath != "" {
+			c.Shell = shellPath
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	setupIcons(config, false)
 
-	return b.String()
+	fmt.Fprintf(os.Stderr, "%s Experiments will be stored in: %s\n", icon("success"), createNewStyle.Render(absPath))
+	if config.Shell != "" {
+		fmt.Fprintf(os.Stderr, "%s Shell override: %s\n", icon("success"), createNewStyle.Render(config.Shell))
+	}
+	fmt.Fprintf(os.Stderr, "%s\n", dimStyle.Render(fmt.Sprintf("(Saved to %s)", getConfigPath())))
 }
 
-func (m model) formatEntry(entry tryEntry, isSelected bool) string {
-	var result strings.Builder
+// measureDir adds up the size of the regular files under path, without
+// following symlinks. A single-file experiment is measured as itself.
+func measureDir(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable parts are skipped rather than failing the whole walk
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
 
-	// Icon
-	result.WriteString("📁 ")
+// Limits on measuring one entry for the list; past either the size shows as
+// unknown rather than holding up the entries after it
+const (
+	usageMaxFiles  = 20000
+	usageTimeLimit = time.Second
+)
 
-	// Parse and format the name
-	name := entry.Basename
-	var displayName string
+// dirUsage is an entry's total size and file count, as shown in the list
+type dirUsage struct {
+	Bytes    int64
+	Files    int
+	Complete bool // False if the walk stopped at usageMaxFiles or usageTimeLimit
+}
 
-	if parts := strings.SplitN(name, "-", 4); len(parts) >= 4 &&
-		len(parts[0]) == 4 && len(parts[1]) == 2 && len(parts[2]) == 2 {
-		// Date-prefixed format
-		datePart := strings.Join(parts[:3], "-")
-		namePart := strings.Join(parts[3:], "-")
+// usageCache holds measured sizes by entry path
+type usageCache map[string]dirUsage
 
-		if isSelected {
-			displayName = selectedStyle.Render(
-				dateStyle.Render(datePart) +
-					dimStyle.Render("-") +
-					m.highlightMatches(namePart))
-		} else {
-			displayName = dateStyle.Render(datePart) +
-				dimStyle.Render("-") +
-				m.highlightMatches(namePart)
-		}
-	} else {
-		// Regular name
-		if isSelected {
-			displayName = selectedStyle.Render(m.highlightMatches(name))
-		} else {
-			displayName = m.highlightMatches(name)
-		}
-	}
+// gitStatusTimeout bounds each git call for show_git_status, so a huge or
+// wedged repository shows without a dirty mark instead of stalling the rest
+const gitStatusTimeout = 2 * time.Second
 
-	result.WriteString(displayName)
+// gitState is a checkout's current branch and whether it has uncommitted
+// changes, as shown in the list
+type gitState struct {
+	Branch string // Empty when git couldn't be asked
+	Dirty  bool
+}
 
-	// Add metadata (time and score)
-	timeText := m.formatRelativeTime(entry.MTime)
-	scoreText := fmt.Sprintf("%.1f", entry.Score)
-	metaText := fmt.Sprintf(" %s, score: %s", timeText, scoreText)
+// gitCache holds read git states by entry path
+type gitCache map[string]gitState
 
-	// Calculate padding
-	plainTextLen := len(entry.Basename) + 2 // +2 for emoji
-	metaLen := len(metaText)
-	paddingNeeded := m.width - 2 - plainTextLen - metaLen // -2 for cursor space
-	if paddingNeeded > 0 {
-		result.WriteString(strings.Repeat(" ", paddingNeeded))
+// readGitState asks git for path's branch (or short commit when detached)
+// and whether it has uncommitted changes
+func readGitState(path string) gitState {
+	var state gitState
+	if _, err := exec.LookPath("git"); err != nil {
+		return state
+	}
+	run := func(args ...string) (string, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), gitStatusTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, "git", append([]string{"-C", path}, args...)...).Output()
+		return strings.TrimSpace(string(out)), err
 	}
 
-	result.WriteString(dimStyle.Render(metaText))
-
-	return result.String()
+	branch, err := run("symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil || branch == "" {
+		if branch, err = run("rev-parse", "--short", "HEAD"); err != nil {
+			return state
+		}
+	}
+	state.Branch = branch
+	status, err := run("status", "--porcelain")
+	state.Dirty = err == nil && status != ""
+	return state
 }
 
-func (m model) formatCreateNew(isSelected bool) string {
-	var result strings.Builder
-	var displayText string
-	var iconLen int
+// String renders the state for the list, e.g. "⎇ main*"
+func (g gitState) String() string {
+	text := icon("branch") + " " + g.Branch
+	if g.Dirty {
+		text += "*"
+	}
+	return text
+}
 
-	// Check if search term is a GitHub URL
-	isGH, cloneURL := isGitHubURL(m.searchTerm)
-	
-	if isGH {
-		result.WriteString("📦 ")
-		iconLen = 2
-		repoName := extractRepoName(cloneURL)
-		displayText = fmt.Sprintf("Clone: %s", repoName)
-		if isSelected {
-			result.WriteString(selectedStyle.Render(createNewStyle.Render(displayText)))
-		} else {
-			result.WriteString(createNewStyle.Render(displayText))
+// measureUsage walks path like measureDir, counting files as well, and gives
+// up once it hits the limits above
+func measureUsage(path string) dirUsage {
+	usage := dirUsage{Complete: true}
+	deadline := time.Now().Add(usageTimeLimit)
+	filepath.WalkDir(path, func(_ string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
 		}
-	} else {
-		result.WriteString("✨ ")
-		iconLen = 2
-		if m.searchTerm == "" {
-			displayText = "Create new experiment..."
-		} else {
-			displayText = fmt.Sprintf("Create: %s", m.searchTerm)
+		if d.Type().IsRegular() {
+			usage.Files++
+			if info, err := d.Info(); err == nil {
+				usage.Bytes += info.Size()
+			}
 		}
-		if isSelected {
-			result.WriteString(selectedStyle.Render(createNewStyle.Render(displayText)))
-		} else {
-			result.WriteString(createNewStyle.Render(displayText))
+		if usage.Files >= usageMaxFiles || time.Now().After(deadline) {
+			usage.Complete = false
+			return filepath.SkipAll
 		}
-	}
+		return nil
+	})
+	return usage
+}
 
-	// Padding
-	textLen := len(displayText) + iconLen
-	paddingNeeded := m.width - 2 - textLen // -2 for cursor space
-	if paddingNeeded > 0 {
-		result.WriteString(strings.Repeat(" ", paddingNeeded))
+// typedConfirmFiles is how many files make an experiment worth typing its
+// name to delete
+const typedConfirmFiles = 50
+
+// needsTypedConfirm reports whether deleting entry should take its name
+// rather than a single 'y': checkouts, and directories with more than
+// typedConfirmFiles files
+func needsTypedConfirm(entry tryEntry) bool {
+	if entry.IsFile {
+		return false
 	}
+	if entry.IsRepo {
+		return true
+	}
+	return countFiles(entry.Path, typedConfirmFiles) > typedConfirmFiles
+}
 
-	return result.String()
+// String renders the usage for the list, e.g. "1.2 MB · 34 files", or "—"
+// when the walk was cut short
+func (u dirUsage) String() string {
+	if !u.Complete {
+		return "—"
+	}
+	files := "files"
+	if u.Files == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%s · %d %s", formatSize(u.Bytes), u.Files, files)
 }
 
-func (m model) highlightMatches(text string) string {
-	if m.searchTerm == "" {
-		return text
+// formatSize renders a byte count with a binary unit, e.g. "1.5 MB"
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
 	}
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
 
-	var result strings.Builder
-	queryLower := strings.ToLower(m.searchTerm)
-	queryChars := []rune(queryLower)
-	queryIdx := 0
+// Number of entries shown in each --stats top list
+const statsTopN = 5
 
-	for _, char := range text {
-		if queryIdx < len(queryChars) && strings.ToLower(string(char)) == string(queryChars[queryIdx]) {
-			result.WriteString(matchStyle.Render(string(char)))
-			queryIdx++
-		} else {
-			result.WriteString(string(char))
+// configFileFormat says what kind of config file is at path, for --config show
+func configFileFormat(path string) string {
+	if path == "" {
+		return "none (no home directory)"
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "not created yet"
+	}
+	if err != nil {
+		return fmt.Sprintf("unreadable (%v)", err)
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		if isLegacyPathConfig(strings.TrimSpace(string(data))) {
+			return "legacy plain text (just the path)"
 		}
+		return fmt.Sprintf("invalid JSON (%v)", err)
 	}
-
-	return result.String()
+	return "JSON"
 }
 
-func (m model) formatRelativeTime(t time.Time) string {
-	duration := time.Since(t)
+// handleConfigShow prints the settings in effect and where each came from,
+// for --config show. pathFlag and profile are the --path and --profile given.
+func handleConfigShow(config *Config, format, pathFlag, profile string) {
+	chosen := config.Profiles[profile]
 
+	pathSource := "not set; the first run asks for one"
 	switch {
-	case duration < 10*time.Second:
-		return "just now"
-	case duration < time.Hour:
-		return fmt.Sprintf("%dm ago", int(duration.Minutes()))
-	case duration < 24*time.Hour:
-		return fmt.Sprintf("%dh ago", int(duration.Hours()))
-	case duration < 30*24*time.Hour:
-		return fmt.Sprintf("%dd ago", int(duration.Hours()/24))
-	case duration < 365*24*time.Hour:
-		return fmt.Sprintf("%dmo ago", int(duration.Hours()/(24*30)))
-	default:
-		return fmt.Sprintf("%dy ago", int(duration.Hours()/(24*365)))
+	case pathFlag != "":
+		pathSource = "--path"
+	case profile != "" && chosen.Path != "":
+		pathSource = fmt.Sprintf("profile %q", profile)
+	case os.Getenv("TRY_PATH") != "":
+		pathSource = "TRY_PATH"
+	case config.ReadEnvrc && envrcTryPath() != "":
+		pathSource = ".envrc"
+	case config.Path != "":
+		pathSource = "config file"
 	}
+
+	shell := getShell(config)
+	shellSource := "default"
+	envShell := os.Getenv("TRY_SHELL")
+	if envShell != "" {
+		if shellPath, err := resolveShellPath(envShell); err == nil {
+			envShell = shellPath
+		}
+	}
+	switch {
+	case profile != "" && chosen.Shell != "":
+		shellSource = fmt.Sprintf("profile %q", profile)
+	case config.Shell != "" && config.Shell == envShell:
+		shellSource = "TRY_SHELL"
+	case config.Shell != "":
+		shellSource = "config file"
+	case os.Getenv("SHELL") != "":
+		shellSource = "$SHELL"
+	}
+
+	basePath := getDefaultPath(config)
+	if basePath == "" {
+		basePath = "-"
+	}
+	fmt.Printf("%-13s %s\n", "Config file:", getConfigPath())
+	fmt.Printf("%-13s %s\n", "Format:", format)
+	fmt.Printf("%-13s %s (%s)\n", "Path:", basePath, pathSource)
+	if roots := getRoots(config, basePath); len(roots) > 1 {
+		fmt.Printf("%-13s %s\n", "Other roots:", strings.Join(roots[1:], ", "))
+	}
+	fmt.Printf("%-13s %s (%s)\n", "Shell:", shell, shellSource)
 }
 
-func handleDirectClone(url string, config *Config) {
-	// Validate it's a GitHub URL
-	isGH, cloneURL := isGitHubURL(url)
-	if !isGH {
-		fmt.Fprintf(os.Stderr, "Error: Not a valid GitHub URL: %s\n", url)
+// handleStats prints an overview of the experiments directory
+func handleStats(config *Config) {
+	basePath := getDefaultPath(config)
+	if basePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no experiments directory configured yet")
 		os.Exit(1)
 	}
 
-	// Get base path
+	m := model{
+		basePath: basePath,
+		roots:    getRoots(config, basePath),
+		config:   config,
+	}
+	m.loadTries()
+
+	now := time.Now()
+	var week, month int
+	var total int64
+	sizes := make(map[string]int64, len(m.tries))
+	for _, entry := range m.tries {
+		if now.Sub(entry.CTime) <= 7*24*time.Hour {
+			week++
+		}
+		if now.Sub(entry.CTime) <= 30*24*time.Hour {
+			month++
+		}
+		sizes[entry.Path] = measureDir(entry.Path)
+		total += sizes[entry.Path]
+	}
+
+	fmt.Printf("%-18s %d\n", "Experiments:", len(m.tries))
+	if len(m.roots) > 1 {
+		fmt.Printf("%-18s %s\n", "Roots:", strings.Join(m.roots, ", "))
+	}
+	fmt.Printf("%-18s %d\n", "Created (7 days):", week)
+	fmt.Printf("%-18s %d\n", "Created (30 days):", month)
+	fmt.Printf("%-18s %s\n", "Disk usage:", formatSize(total))
+	if len(m.tries) == 0 {
+		return
+	}
+
+	tries := append([]tryEntry(nil), m.tries...)
+	sort.SliceStable(tries, func(i, j int) bool { return sizes[tries[i].Path] > sizes[tries[j].Path] })
+	fmt.Println("\nLargest:")
+	for _, entry := range tries[:min(statsTopN, len(tries))] {
+		fmt.Printf("  %10s  %s\n", formatSize(sizes[entry.Path]), cleanDisplayName(entry.Basename))
+	}
+
+	sort.SliceStable(tries, func(i, j int) bool { return tries[i].MTime.After(tries[j].MTime) })
+	fmt.Println("\nRecently accessed:")
+	for _, entry := range tries[:min(statsTopN, len(tries))] {
+		fmt.Printf("  %10s  %s\n", m.formatRelativeTime(entry.MTime), cleanDisplayName(entry.Basename))
+	}
+}
+
+// printCurrent prints the name of the experiment the working directory is
+// in, for prompts. Outside every root it prints nothing and still succeeds.
+func printCurrent(config *Config) {
 	basePath := getDefaultPath(config)
 	if basePath == "" {
-		basePath = promptForPath()
-		// Reload config after prompting
-		var err error
-		config, err = getResolvedConfig()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: failed to reload config after setting path: %v\n", err)
-			os.Exit(1)
+		return
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+
+	for _, root := range getRoots(config, basePath) {
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			root = resolved
+		}
+		rel, err := filepath.Rel(root, cwd)
+		if err != nil || rel == "." || !isUnder(root, cwd) {
+			continue
+		}
+		parts := strings.Split(rel, string(filepath.Separator))
+		if parts[0] == trashDirName {
+			// Deleted experiments aren't experiments any more
+			continue
+		}
+		// Scratch experiments live one level further down
+		if parts[0] == scratchDirName && len(parts) > 1 {
+			parts = parts[1:]
 		}
+		fmt.Println(parts[0])
+		return
 	}
+}
 
-	// Perform the clone
-	fullPath, err := performClone(cloneURL, basePath)
+// handleOpenURL opens the upstream page of the best match for name
+func handleOpenURL(name string, config *Config) {
+	basePath := getDefaultPath(config)
+	if basePath == "" {
+		fmt.Fprintln(os.Stderr, "Error: no experiments directory configured yet")
+		os.Exit(1)
+	}
+
+	m := model{
+		searchTerm: name,
+		basePath:   basePath,
+		roots:      getRoots(config, basePath),
+		config:     config,
+		sortMode:   config.Sort,
+	}
+	m.loadTries()
+	m.filterTries()
+	if len(m.filteredTries) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no experiment matches %q\n", name)
+		os.Exit(1)
+	}
+
+	url, err := repoWebURL(m.filteredTries[0])
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-
-	// Change to the directory
-	if err := os.Chdir(fullPath); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: couldn't change directory: %v\n", err)
+	fmt.Fprintf(os.Stderr, "%s Opening %s\n", icon("clone"), url)
+	if err := openURL(url); err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening browser: %v\n", err)
 		os.Exit(1)
 	}
+}
 
-	// Launch a new shell
-	shell := getShell(config)
+// listTries prints the basenames of matching experiments, best match first
+func listTries(searchTerm string, config *Config, source Source, since time.Time, grep *regexp.Regexp) {
+	basePath := getDefaultPath(config)
+	if basePath == "" {
+		// Nothing configured yet, so there is nothing to list
+		if jsonOutput {
+			fmt.Println("[]")
+		}
+		return
+	}
 
-	fmt.Printf("\n✨ Successfully cloned and entering %s\n\n", filepath.Base(fullPath))
+	m := model{
+		searchTerm: searchTerm,
+		basePath:   basePath,
+		roots:      getRoots(config, basePath),
+		config:     config,
+		sortMode:   config.Sort,
+		source:     source,
+		since:      since,
+		grep:       grep,
+	}
+	m.loadTries()
+	m.filterTries()
 
-	cmd := exec.Command(shell)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	cmd.Dir = fullPath
+	if jsonOutput {
+		data, err := json.MarshalIndent(m.filteredTries, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
 
-	if err := cmd.Run(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error launching shell: %v\n", err)
-		os.Exit(1)
+	for _, entry := range m.filteredTries {
+		if porcelainOutput {
+			// Column order is part of the interface: append new columns, never reorder
+			fmt.Printf("%s\t%s\t%.3f\t%t\n", entry.Path, entry.MTime.Format(time.RFC3339), entry.Score, entry.IsRepo)
+			continue
+		}
+		fmt.Println(entry.Basename)
 	}
 }
 
@@ -1312,9 +6410,55 @@ func main() {
 	showHelp := false
 	showVersion := false
 	cloneURL := ""
+	basePathFlag := ""
+	profile := ""
 	selectOnly := false
+	listOnly := false
+	scratch := false
+	today := false
+	ascii := false
+	root := false
+	run := false
+	noTouch := false
+	submodules := false
+	depth := -1 // Unset; --full makes it 0
+	branch := ""
+	loop := false
+	only := ""
+	sortMode := ""
+	forceClone := false
+	ssh := false
+	gitignore := ""
+	var initGit *bool // --git or --no-git, if given
+	template := ""
+	openURLName := ""
+	fromFile := ""
+	newName := ""
+	last := false
+	grepPattern := ""
+	openEditor := false
+	current := false
+	sinceLast := false
+	fromClipboard := false
+	stats := false
+	emptyTrash := false
+	configShow := false
+	prune := false
+	olderThan := -1 // Days; unset keeps prune_after_days
+	completionShell := ""
 
 	args := os.Args[1:]
+
+	// `try init` has its own arguments and must work before any config exists
+	if len(args) > 0 && args[0] == "init" {
+		handleInit(args[1:])
+		return
+	}
+	if len(args) == 1 && (args[0] == "setup" || args[0] == "--setup") {
+		handleSetup()
+		return
+	}
+
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
 		switch arg {
@@ -1324,15 +6468,199 @@ func main() {
 			showVersion = true
 		case "--select-only", "-s":
 			selectOnly = true
+		case "--porcelain":
+			porcelainOutput = true
+		case "--json":
+			jsonOutput = true
+		case "--yes", "-y":
+			assumeYes = true
 		case "--clone", "-c":
 			// Get the next argument as the URL
 			if i+1 < len(args) {
-				cloneURL = args[i+1]
-				i++ // Skip the URL argument
+				cloneURL = args[i+1]
+				i++ // Skip the URL argument
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --clone requires a URL argument")
+				os.Exit(1)
+			}
+		case "--path", "-p":
+			if i+1 < len(args) && args[i+1] != "" {
+				basePathFlag = args[i+1]
+				i++
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a directory for the experiments\n", args[i])
+				os.Exit(1)
+			}
+		case "--profile":
+			if i+1 < len(args) && args[i+1] != "" {
+				profile = args[i+1]
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --profile requires the name of a profile in the config file")
+				os.Exit(1)
+			}
+		case "--list", "-l":
+			listOnly = true
+		case "--scratch":
+			scratch = true
+		case "--root":
+			root = true
+		case "--run":
+			run = true
+		case "--no-touch":
+			noTouch = true
+		case "--loop":
+			loop = true
+		case "--submodules":
+			submodules = true
+		case "--full":
+			depth = 0
+		case "--force":
+			forceClone = true
+		case "--ssh":
+			ssh = true
+		case "--branch", "-b":
+			if i+1 < len(args) && args[i+1] != "" {
+				branch = args[i+1]
+				i++
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a branch name\n", args[i])
+				os.Exit(1)
+			}
+		case "--depth":
+			if i+1 < len(args) {
+				n, err := strconv.Atoi(args[i+1])
+				if err != nil || n < 0 {
+					fmt.Fprintf(os.Stderr, "Error: --depth requires a number of commits (0 for the full history), got %q\n", args[i+1])
+					os.Exit(1)
+				}
+				depth = n
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --depth requires a number of commits (0 for the full history)")
+				os.Exit(1)
+			}
+		case "--only-repos", "--only-scratch":
+			kind := strings.TrimPrefix(arg, "--only-")
+			kind = strings.TrimSuffix(kind, "s")
+			if only != "" && only != kind {
+				fmt.Fprintln(os.Stderr, "Error: --only-repos and --only-scratch can't be combined")
+				os.Exit(1)
+			}
+			only = kind
+		case "--ascii":
+			ascii = true
+		case "--sort":
+			if i+1 < len(args) {
+				sortMode = args[i+1]
+				i++
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: --sort requires one of: %s\n", strings.Join(sortModes, ", "))
+				os.Exit(1)
+			}
+		case "--stats":
+			stats = true
+		case "--empty-trash":
+			emptyTrash = true
+		case "--prune":
+			prune = true
+		case "--config":
+			if i+1 < len(args) && args[i+1] == "show" {
+				configShow = true
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --config requires a command: show")
+				os.Exit(1)
+			}
+		case "--older-than":
+			if i+1 < len(args) {
+				days, err := parseAgeDays(args[i+1])
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: --older-than %v\n", err)
+					os.Exit(1)
+				}
+				olderThan = days
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --older-than requires an age, e.g. 90d")
+				os.Exit(1)
+			}
+		case "--current":
+			current = true
+		case "--since-last":
+			sinceLast = true
+		case "--from-clipboard":
+			fromClipboard = true
+		case "--open-url":
+			if i+1 < len(args) {
+				openURLName = args[i+1]
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --open-url requires an experiment name")
+				os.Exit(1)
+			}
+		case "--from-file":
+			if i+1 < len(args) {
+				fromFile = args[i+1]
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --from-file requires a path list file")
+				os.Exit(1)
+			}
+		case "--grep":
+			if i+1 < len(args) && args[i+1] != "" {
+				grepPattern = args[i+1]
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --grep requires a pattern to look for in files")
+				os.Exit(1)
+			}
+		case "--editor", "-e":
+			openEditor = true
+		case "--last":
+			last = true
+		case "--new":
+			if i+1 < len(args) && strings.TrimSpace(args[i+1]) != "" {
+				newName = strings.TrimSpace(args[i+1])
+				i++
+			} else {
+				fmt.Fprintln(os.Stderr, "Error: --new requires a name for the experiment")
+				os.Exit(1)
+			}
+		case "--git", "--no-git":
+			enabled := args[i] == "--git"
+			initGit = &enabled
+		case "--template":
+			if i+1 < len(args) {
+				template = args[i+1]
+				i++
 			} else {
-				fmt.Fprintln(os.Stderr, "Error: --clone requires a URL argument")
+				fmt.Fprintln(os.Stderr, "Error: --template requires the name of a directory in template_dir")
+				os.Exit(1)
+			}
+		case "--gitignore":
+			if i+1 < len(args) {
+				gitignore = args[i+1]
+				i++
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: --gitignore requires one of: %s\n", strings.Join(gitignoreLangs, ", "))
+				os.Exit(1)
+			}
+		case "--completions", "--completion":
+			if i+1 < len(args) {
+				completionShell = args[i+1]
+				i++
+			} else {
+				fmt.Fprintf(os.Stderr, "Error: %s requires a shell argument (bash, zsh or fish)\n", args[i])
 				os.Exit(1)
 			}
+		case "today":
+			// Subcommand only when it's the sole positional argument (see below)
+			if searchTerm == "" && !today {
+				today = true
+			} else {
+				searchTerm += arg + " "
+			}
 		default:
 			if !strings.HasPrefix(arg, "-") {
 				searchTerm += arg + " "
@@ -1340,12 +6668,44 @@ func main() {
 		}
 	}
 
+	// "try today redis" is a plain search, not the today subcommand
+	if today && searchTerm != "" {
+		searchTerm = "today " + searchTerm
+		today = false
+	}
+
+	if porcelainOutput && !selectOnly && !listOnly {
+		fmt.Fprintln(os.Stderr, "Error: --porcelain only applies to --select-only and --list")
+		os.Exit(1)
+	}
+	if jsonOutput && (!listOnly || porcelainOutput) {
+		fmt.Fprintln(os.Stderr, "Error: --json only applies to --list, without --porcelain")
+		os.Exit(1)
+	}
+
 	// Handle version flag early (doesn't need config)
 	if showVersion {
-		fmt.Printf("try version %s\n", version)
+		fmt.Printf("try version %s (%s, %s/%s)\n", buildVersion(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+		return
+	}
+
+	// Completion scripts are static apart from calling back into try --list
+	if completionShell != "" {
+		script, err := completionScript(completionShell)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(script)
 		return
 	}
 
+	// Describe the file before loading it, since a broken one gets moved aside
+	configFormat := ""
+	if configShow {
+		configFormat = configFileFormat(getConfigPath())
+	}
+
 	// Load config once at startup
 	config, err := getResolvedConfig()
 	if err != nil {
@@ -1353,19 +6713,220 @@ func main() {
 		os.Exit(1)
 	}
 
+	setupIcons(config, ascii)
+
+	// Command-line flags override the resolved config for this invocation
+	if profile != "" {
+		applyProfile(config, profile)
+	}
+	if basePathFlag != "" {
+		// Like a one-entry TRY_PATH, it replaces every configured root
+		path, err := sanitizePath(basePathFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --path: %v\n", err)
+			os.Exit(1)
+		}
+		if err := os.MkdirAll(path, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating directory %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		config.Path = path
+		config.Paths = nil
+	}
+	if profile != "" || basePathFlag != "" {
+		// The roots changed after the config was validated
+		if err := validateTrashDir(config.TrashDir, append([]string{config.Path}, config.Paths...)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if sortMode != "" {
+		config.Sort = sortMode
+	}
+	if config.Sort != "" && !isValidSortMode(config.Sort) {
+		fmt.Fprintf(os.Stderr, "Error: unknown sort mode %q (use %s)\n", config.Sort, strings.Join(sortModes, ", "))
+		os.Exit(1)
+	}
+	if noTouch {
+		touch := false
+		config.TouchOnAccess = &touch
+	}
+	if submodules {
+		config.CloneSubmodules = true
+	}
+	if depth >= 0 {
+		config.CloneDepth = &depth
+	}
+	config.CloneBranch = branch
+	config.ForceClone = forceClone
+	if ssh {
+		config.CloneProtocol = "ssh"
+	}
+	if initGit != nil {
+		config.InitGit = *initGit
+	}
+	if olderThan >= 0 {
+		if !prune {
+			fmt.Fprintln(os.Stderr, "Error: --older-than only applies to --prune")
+			os.Exit(1)
+		}
+		config.PruneAfterDays = olderThan
+	}
+	if openEditor {
+		config.OpenInEditor = true
+	}
+	if template != "" {
+		if config.TemplateDir == "" {
+			fmt.Fprintln(os.Stderr, "Error: --template needs template_dir set in the config file")
+			os.Exit(1)
+		}
+		if template != filepath.Base(template) || template == ".." {
+			fmt.Fprintf(os.Stderr, "Error: --template takes a directory name in %s, got %q\n", config.TemplateDir, template)
+			os.Exit(1)
+		}
+		config.TemplateDir = filepath.Join(config.TemplateDir, template)
+	}
+	if gitignore != "" {
+		if _, ok := gitignoreTemplates[strings.ToLower(gitignore)]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown gitignore template %q (use %s)\n", gitignore, strings.Join(gitignoreLangs, ", "))
+			os.Exit(1)
+		}
+		config.DefaultGitignore = gitignore
+	}
+
 	if showHelp {
 		printHelp(config)
 		return
 	}
 
+	if configShow {
+		handleConfigShow(config, configFormat, basePathFlag, profile)
+		return
+	}
+
 	// Handle direct clone operation
 	if cloneURL != "" {
 		handleDirectClone(cloneURL, config)
 		return
 	}
 
+	if scratch {
+		handleScratch(config)
+		return
+	}
+
+	if today {
+		handleToday(config, selectOnly)
+		return
+	}
+
+	if newName != "" {
+		handleNew(newName, config, selectOnly)
+		return
+	}
+
+	if last {
+		handleLast(config, selectOnly, run)
+		return
+	}
+
+	if stats {
+		handleStats(config)
+		return
+	}
+
+	if emptyTrash {
+		handleEmptyTrash(config)
+		return
+	}
+
+	if prune {
+		handlePrune(config)
+		return
+	}
+
+	if current {
+		printCurrent(config)
+		return
+	}
+
+	if openURLName != "" {
+		handleOpenURL(openURLName, config)
+		return
+	}
+
+	if root {
+		basePath, config := requireBasePath(config)
+		enterBasePath(basePath, config, selectOnly)
+		return
+	}
+
 	searchTerm = strings.TrimSpace(searchTerm)
 
+	if fromClipboard {
+		text, err := readClipboard()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: can't read the clipboard: %v\n", err)
+			os.Exit(1)
+		}
+		searchTerm = clipboardSearch(text)
+		if searchTerm == "" {
+			fmt.Fprintln(os.Stderr, "Error: the clipboard is empty")
+			os.Exit(1)
+		}
+	}
+
+	// An exact alias skips the selector and goes straight to its experiment
+	if !listOnly && only == "" {
+		if path, ok := resolveAlias(config, getDefaultPath(config), searchTerm); ok {
+			sel := &selection{Type: "cd", Path: path}
+			if info, err := os.Stat(path); err == nil && info.Mode().IsRegular() {
+				sel.Type = "edit"
+			} else if run {
+				sel.Type = "run"
+			}
+			handleSelection(sel, getDefaultPath(config), config, selectOnly)
+			return
+		}
+	}
+
+	if only != "" {
+		// Same as typing the kind token, so it shows in the search box and can be removed
+		searchTerm = only + ":" + searchTerm
+	}
+
+	// An explicit path list replaces scanning the roots
+	var source Source
+	if fromFile != "" {
+		if _, err := os.Stat(fromFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: can't read path list: %v\n", err)
+			os.Exit(1)
+		}
+		source = &fileSource{path: fromFile}
+	}
+
+	var grep *regexp.Regexp
+	if grepPattern != "" {
+		re, err := regexp.Compile(grepPattern)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --grep pattern: %v\n", err)
+			os.Exit(1)
+		}
+		grep = re
+	}
+
+	// Only browsing moves the last-run mark, so --list and completions leave it be
+	var since time.Time
+	if sinceLast {
+		since = readLastRun()
+	}
+
+	// Non-interactive listing doesn't need a TTY
+	if listOnly {
+		listTries(searchTerm, config, source, since, grep)
+		return
+	}
+
 	// Check if we have a TTY
 	if !checkTTYRequirements(selectOnly) {
 		fmt.Fprintln(os.Stderr, "Error: try requires an interactive terminal")
@@ -1374,6 +6935,32 @@ func main() {
 
 	// Run the TUI
 	m := initialModel(searchTerm, config)
+	if source != nil {
+		m.source = source
+		m.loadTries()
+		m.filterTries()
+		// The warnings above are hidden once the selector takes the screen
+		if list, ok := source.(*fileSource); ok && list.skipped > 0 {
+			m.statusMsg = fmt.Sprintf("Skipped %d missing path(s) from %s", list.skipped, filepath.Base(fromFile))
+		}
+	}
+	if grep != nil {
+		m.grep = grep
+		m.loadTries()
+		m.filterTries()
+		if len(m.tries) == 0 {
+			m.statusMsg = "No experiment has a file matching " + grep.String()
+		}
+	}
+	if sinceLast {
+		m.since = since
+		m.filterTries()
+		if since.IsZero() {
+			m.statusMsg = "No earlier run recorded, so everything is listed"
+		}
+	}
+	m.runOnSelect = run
+	m.loop = loop
 	var p *tea.Program
 	if selectOnly {
 		// Output TUI to stderr so stdout can be piped
@@ -1390,6 +6977,8 @@ func main() {
 		os.Exit(1)
 	}
 
+	writeLastRun(time.Now())
+
 	var ok bool
 	m, ok = finalModel.(model)
 	if !ok {
@@ -1397,128 +6986,196 @@ func main() {
 		os.Exit(1)
 	}
 
+	for _, path := range m.loopPrinted {
+		fmt.Println(path)
+	}
+	printSessionSummary(m.actions, m.selected)
+
 	// Handle the selection
 	if m.selected != nil {
-		switch m.selected.Type {
-		case "cd":
-			// Touch the directory to update access time
-			if err := os.Chtimes(m.selected.Path, time.Now(), time.Now()); err != nil {
-				// Non-fatal, just log it
-				if !selectOnly {
-					fmt.Fprintf(os.Stderr, "Warning: couldn't update access time: %v\n", err)
-				}
-			}
+		handleSelection(m.selected, m.basePath, m.config, selectOnly)
+	}
+}
 
-			if selectOnly {
-				// Just output the path and exit
-				fmt.Println(m.selected.Path)
-				os.Exit(0)
-			}
+// printSessionSummary lists what the session did on stderr, ending with the
+// create or clone about to happen. A session that did one thing or less is left
+// alone, since its result is on screen anyway.
+func printSessionSummary(actions []string, sel *selection) {
+	if sel != nil && sel.Type == "mkdir" {
+		actions = append(actions, "creating "+filepath.Base(sel.Path))
+	} else if sel != nil && sel.Type == "clone" {
+		actions = append(actions, "cloning "+sel.CloneURL)
+	}
+	if len(actions) < 2 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "This session:")
+	for _, action := range actions {
+		fmt.Fprintf(os.Stderr, "  %s\n", action)
+	}
+}
 
-			// Change to the directory
-			if err := os.Chdir(m.selected.Path); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: couldn't change directory: %v\n", err)
-				os.Exit(1)
-			}
+// lastRunFileName is kept next to the config file and holds when the selector
+// last ran, for --since-last
+const lastRunFileName = "last-run"
 
-			// Launch a new shell in the selected directory
-			shell := getShell(m.config)
+func lastRunPath() string {
+	configPath := getConfigPath()
+	if configPath == "" {
+		return ""
+	}
+	return filepath.Join(filepath.Dir(configPath), lastRunFileName)
+}
 
-			fmt.Printf("\n🚀 Entering %s\n\n", filepath.Base(m.selected.Path))
+// readLastRun returns when the selector last ran, or the zero time if that's unknown
+func readLastRun() time.Time {
+	path := lastRunPath()
+	if path == "" {
+		return time.Time{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
 
-			cmd := exec.Command(shell)
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			cmd.Dir = m.selected.Path
+// writeLastRun records t as the last run. It's only a convenience, so a
+// directory that can't be written is ignored.
+func writeLastRun(t time.Time) {
+	path := lastRunPath()
+	if path == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, []byte(t.Format(time.RFC3339)+"\n"), 0644)
+}
 
-			if err := cmd.Run(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error launching shell: %v\n", err)
-				os.Exit(1)
-			}
+// buildVersion is the stamped version, or for an unstamped `go install`
+// the module version Go recorded
+func buildVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
 
-		case "mkdir":
-			// Create the new directory
-			if err := os.MkdirAll(m.selected.Path, 0755); err != nil {
-				fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
-				os.Exit(1)
-			}
+// jsonOutput makes --list print the entries as a JSON array, for --json
+var jsonOutput bool
 
-			// Touch it
-			if err := os.Chtimes(m.selected.Path, time.Now(), time.Now()); err != nil {
-				// Non-fatal, just log it
-				if !selectOnly {
-					fmt.Fprintf(os.Stderr, "Warning: couldn't update access time: %v\n", err)
-				}
-			}
+// porcelainOutput makes select-only output say what was done and --list print
+// columns, for --porcelain
+var porcelainOutput bool
 
-			if selectOnly {
-				// Just output the path and exit
-				fmt.Println(m.selected.Path)
-				os.Exit(0)
-			}
+// printSelected writes a select-only result to stdout: the path, or with
+// --porcelain a tab-separated line of the action, the path and (for clones)
+// the URL, e.g. "clone\t/path\thttps://github.com/user/repo"
+func printSelected(sel *selection) {
+	if !porcelainOutput {
+		fmt.Println(sel.Path)
+		return
+	}
+	action := sel.Type
+	if action == "mkdir" {
+		action = "create"
+	}
+	fields := []string{action, sel.Path}
+	if sel.CloneURL != "" {
+		fields = append(fields, sel.CloneURL)
+	}
+	fmt.Println(strings.Join(fields, "\t"))
+}
 
-			// Change to it
-			if err := os.Chdir(m.selected.Path); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: couldn't change directory: %v\n", err)
-				os.Exit(1)
-			}
+// handleSelection carries out what was picked in the selector. The target is
+// created or cloned first if needed; in select-only mode its path is then
+// printed and nothing is launched, whatever the action, so shell integrations
+// decide what happens next.
+func handleSelection(sel *selection, basePath string, config *Config, selectOnly bool) {
+	path := sel.Path
+	switch sel.Type {
+	case "mkdir":
+		// Create the new directory
+		if err := os.MkdirAll(path, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating directory: %v\n", err)
+			os.Exit(1)
+		}
+		scaffoldExperiment(path, config)
 
-			// Launch a new shell
-			shell := getShell(m.config)
+	case "clone":
+		// Clone the repository
+		targetPath, err := performClone(sel.CloneURL, basePath, config, isInteractive())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		path = targetPath
+	}
+	if sel.Type == "mkdir" || sel.Type == "clone" {
+		runPostCreateHook(path, config, selectOnly)
+	}
 
-			fmt.Printf("\n✨ Created and entering %s\n\n", filepath.Base(m.selected.Path))
+	// Touch it so it ranks as recently used; the base path itself isn't an experiment
+	if sel.Type != "root" {
+		touchPath(path, config, selectOnly)
+	}
 
-			cmd := exec.Command(shell)
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			cmd.Dir = m.selected.Path
+	if selectOnly {
+		// Just output the path and exit
+		printSelected(&selection{Type: sel.Type, Path: path, CloneURL: cloneTransportURL(sel.CloneURL, config)})
+		return
+	}
 
-			if err := cmd.Run(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error launching shell: %v\n", err)
-				os.Exit(1)
-			}
-			
-		case "clone":
-			// Clone GitHub repository
-			cloneURL := m.selected.CloneURL
+	switch sel.Type {
+	case "edit":
+		// Single-file experiment: open it in the editor
+		fmt.Fprintf(os.Stderr, "\n%s Opening %s\n\n", icon("edit"), filepath.Base(path))
+		if err := launchEditor(path, config); err != nil {
+			fmt.Fprintf(os.Stderr, "Error launching editor: %v\n", err)
+			os.Exit(1)
+		}
 
-			// Perform the clone
-			targetPath, err := performClone(cloneURL, m.basePath)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-				os.Exit(1)
-			}
+	case "run":
+		runProjectCommand(path, config)
 
-			if selectOnly {
-				// Just output the path and exit
-				fmt.Println(targetPath)
-				os.Exit(0)
-			}
+	case "root":
+		enterBasePath(path, config, false)
 
-			// Change to the directory
-			if err := os.Chdir(targetPath); err != nil {
-				fmt.Fprintf(os.Stderr, "Error: couldn't change directory: %v\n", err)
+	default:
+		if config != nil && config.OpenInEditor {
+			// cd, mkdir and clone open the experiment in the editor instead of a shell
+			fmt.Fprintf(os.Stderr, "\n%s Opening %s in the editor\n\n", icon("edit"), filepath.Base(path))
+			if err := launchEditor(path, config); err != nil {
+				fmt.Fprintf(os.Stderr, "Error launching editor: %v\n", err)
 				os.Exit(1)
 			}
+			return
+		}
 
-			// Launch a new shell
-			shell := getShell(m.config)
-			
-			fmt.Printf("\n✨ Successfully cloned and entering %s\n\n", filepath.Base(targetPath))
-			
-			cmd := exec.Command(shell)
-			cmd.Stdin = os.Stdin
-			cmd.Stdout = os.Stdout
-			cmd.Stderr = os.Stderr
-			cmd.Dir = targetPath
-			
-			if err := cmd.Run(); err != nil {
-				fmt.Fprintf(os.Stderr, "Error launching shell: %v\n", err)
-				os.Exit(1)
-			}
+		// cd, mkdir and clone all end in a shell inside the experiment
+		if err := os.Chdir(path); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: couldn't change directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		switch sel.Type {
+		case "mkdir":
+			fmt.Fprintf(os.Stderr, "\n%s Created and entering %s\n\n", icon("create"), filepath.Base(path))
+		case "clone":
+			fmt.Fprintf(os.Stderr, "\n%s Successfully cloned and entering %s\n\n", icon("create"), filepath.Base(path))
+		default:
+			fmt.Fprintf(os.Stderr, "\n%s Entering %s\n\n", icon("enter"), filepath.Base(path))
 		}
+
+		exitAfterShell(launchShell(path, config), config)
 	}
 }
 
@@ -1528,8 +7185,11 @@ func printHelp(config *Config) {
 		basePath = "Not configured (will prompt on first use)"
 	}
 	shellInfo := ""
+	if config != nil && len(config.Paths) > 0 {
+		shellInfo = fmt.Sprintf("\n  Other roots: %s", strings.Join(config.Paths, ", "))
+	}
 	if config != nil && config.Shell != "" {
-		shellInfo = fmt.Sprintf("\n  Shell override: %s", config.Shell)
+		shellInfo += fmt.Sprintf("\n  Shell override: %s", config.Shell)
 	}
 	configPath := getConfigPath()
 	help := fmt.Sprintf(`📁 try - Quick Experiment Directories
@@ -1540,30 +7200,132 @@ Perfect for people with ADHD who need quick, organized workspaces.
 USAGE:
   try [search_term]           Launch selector with optional search
   try --select-only, -s       Output selected path instead of launching shell
-  try --clone <github-url>    Clone a GitHub repository
+  try -s --porcelain          Output "<action>\t<path>[\t<url>]" instead, where
+                              action is cd, create, clone, edit, run or root
+  try --clone <url>           Clone a GitHub, GitLab, Bitbucket or Codeberg
+                              repository (or gh:, gl:, bb:, cb:user/repo)
+  try --clone <url> --submodules
+                              Also clone submodules
+  try --clone <url> -b <name> Clone and check out that branch only
+                              (--branch; also for clones from the selector)
+  try --clone <url> --full    Clone the whole history (or --depth <n>
+                              for the last n commits; default clone_depth 1)
+  try --clone <url> --force   Clone again even if an experiment already has
+                              it (otherwise that one is entered)
+  try --clone <url> --ssh     Clone over SSH instead of https
+                              (or clone_protocol "ssh"; selector clones too)
+  try --from-clipboard        Search for the clipboard: a copied repo URL
+                              offers a clone, anything else a new experiment
+  try --path, -p <dir>        Use <dir> as the experiments directory for this
+                              run, instead of TRY_PATH or the config
+  try --profile <name>        Use that profile's path and shell (profiles
+                              in the config file)
+  try --list, -l              List experiment names and exit
+  try --list --json           List the entries (name, path, times, score, ...)
+                              as a JSON array
+  try --list --porcelain      List "<path>\t<mtime>\t<score>\t<is-repo>" lines
+  try --stats                 Print counts, disk usage and the largest and
+                              most recent experiments
+  try --empty-trash           Permanently remove deleted experiments
+  try --config show           Print the config file and format, and the path
+                              and shell in effect with where each comes from
+  try --prune                 Move empty experiments to the trash, after
+                              listing them and asking once (--yes skips it)
+  try --prune --older-than 90d
+                              ...and ones unchanged for 90 days (or 12w, 1y;
+                              default prune_after_days)
+  try --current               Print the experiment the current directory is in
+                              (nothing outside the experiments directory)
+  try --grep <pattern>        Only list experiments with a file matching the
+                              regular expression, most matching lines first
+  try --since-last            Only list experiments changed since the last
+                              time the selector was used
+  try --new <name>            Create YYYY-MM-DD-<name> and enter it (-2, -3...
+                              if it already exists), without the selector
+  try --last                  Enter the most recently changed experiment,
+                              without the selector (-s prints its path)
+  try --scratch               Enter a throwaway experiment, removed on exit
+  try --root                  Open a shell in the base path itself
+  try --editor, -e            Open the pick in the editor instead of a shell
+                              (editor, else $VISUAL or $EDITOR)
+  try --open-url <name>       Open a cloned experiment's upstream page
+  try --run [search_term]     Run the project type's command in the selection
+  try --loop                  Return to the list after each pick (loop_action)
+  try --only-repos            Only list git checkouts (same as a repo: search)
+  try --only-scratch          Only list non-repo experiments (scratch:)
+  try today                   Enter today's experiment (YYYY-MM-DD-scratch)
+  try init <path> [--shell S] Save the base path (and shell) to the config
+  try setup, --setup          Re-run the first-run questions interactively
+  try --yes, -y               Accept the first-run defaults (or --prune's
+                              list) without prompting
+  try --completions <shell>   Print completion script (bash, zsh, fish);
+                              --completion works too
+  try --ascii                 Use ASCII markers instead of emoji
+  try --sort <mode>           Order by score (default), name, created or accessed
+  try --no-touch              Don't update the access time of what you open
+  try --gitignore <lang>      Add a starter .gitignore to new experiments
+                              (go, node, python, rust)
+  try --template <name>       Copy template_dir/<name> into new experiments
+                              (instead of template_dir itself)
+  try --git, --no-git         Run git init in new experiments, or don't
+                              (overrides init_git)
+  try --from-file <list>      Browse the paths listed in a file (one per line)
+                              instead of the experiments directory
   try --version, -v           Show version information
   try --help                  Show this help
 
 FEATURES:
   • Fuzzy search with smart scoring
+  • Space-separated search words each match, in any order
   • Automatic date prefixing (YYYY-MM-DD)
   • Time-based sorting (recent = higher)
-  • GitHub repository cloning
+  • GitHub, GitLab, Bitbucket and Codeberg cloning
+  • repo: / scratch: search tokens to show only checkouts or only the rest
+  • date: tokens (date:jan, date:2024-03, date:today) narrow by the name's date
 
 NAVIGATION:
   ↑/↓          Navigate entries
+  PgUp/PgDn    Move a page up or down
+  Home/End     Jump to the first entry or the create row
   Ctrl+j/k     Navigate entries (vim-style)
   Enter        Select directory or create new
   Ctrl+N       Create new experiment (quick)
-  Ctrl+D       Delete selected directory
+  Ctrl+D       Delete selected directory (to the trash)
+  Ctrl+Space   Mark the selection to delete several at once
+  Ctrl+Z       Undo the last delete (u in search_mode "explicit")
+  Ctrl+W       Show the trash in the list too; Enter restores and opens
+  Ctrl+O       Open a shell in the base path itself
+  Ctrl+G       Open the selected clone's upstream page in the browser
+  Ctrl+X       Run the project type's command (run_commands) in the selection
   Backspace    Delete search character
+  Ctrl+A       Give the selected experiment an alias (try <alias> jumps to it)
+  Ctrl+E       Rename the selected experiment (r in search_mode "explicit")
+  Ctrl+F       Bookmark the selection, or remove its bookmark
+  Ctrl+B       Show only bookmarked experiments (again to show all)
+  Ctrl+Y       Pin the selection to the top of the list, or unpin it
+  Ctrl+T       Set the selection's own shell and editor (.try-meta)
+  Ctrl+V       Search for the clipboard contents (repo URL or name)
+  Tab          Hide or show the preview pane (100+ columns wide)
   Ctrl+U       Clear search
+  Ctrl+/       Cycle match mode (fuzzy, substring, regex)
+  Ctrl+R       Switch between regex and fuzzy matching
+  Ctrl+S       Cycle sort order (score, name, created, accessed)
+  1-9, Alt+1-9 Jump to / open a numbered row (number_select)
+  /            Focus search, Esc to leave it (search_mode "explicit")
   ESC or q     Cancel and exit
 
+  Most keys can be rebound with keybindings in the config file.
+
 CONFIGURATION:
   Environment variables (override config file):
-    TRY_PATH   - Base directory for experiments
-    TRY_SHELL  - Shell to use (overrides $SHELL)
+    TRY_PATH   - Base directory for experiments (a %c-separated list
+                 browses several roots; new ones go to the first)
+    TRY_SHELL  - Shell to use, a path or a name looked up in PATH
+                 (overrides the config and $SHELL; ignored with a
+                 warning if it isn't found)
+    TRY_ASCII  - 1 for ASCII markers, 0 to force emoji
+    TRY_CONFIG - Config file to use instead of the default location
+    TRY_CLONE_TIMEOUT - Seconds a clone may take (0: 120, negative: none)
 
   Config file: %s
   Current path: %s%s
@@ -1579,11 +7341,199 @@ EXAMPLES:
 
 First launch automatically creates the base directory.
 Selected directories open in a new shell session.
-`, configPath, basePath, shellInfo)
+`, os.PathListSeparator, configPath, basePath, shellInfo)
 
 	fmt.Print(help)
 }
 
+// cliFlag describes a command-line flag for the generated completion scripts
+type cliFlag struct {
+	Long    string
+	Short   string
+	Arg     string   // Argument placeholder, empty for boolean flags; "dir" and "file" complete paths
+	Choices []string // Fixed argument values to offer, if any
+	Desc    string
+}
+
+var cliFlags = []cliFlag{
+	{Long: "--help", Short: "-h", Desc: "Show help"},
+	{Long: "--version", Short: "-v", Desc: "Show version information"},
+	{Long: "--select-only", Short: "-s", Desc: "Output selected path instead of launching shell"},
+	{Long: "--porcelain", Desc: "Tab-separated output for --select-only and --list"},
+	{Long: "--clone", Short: "-c", Arg: "url", Desc: "Clone a GitHub, GitLab, Bitbucket or Codeberg repository"},
+	{Long: "--submodules", Desc: "Also clone the repository's submodules"},
+	{Long: "--full", Desc: "Clone the full history instead of the latest commit"},
+	{Long: "--force", Desc: "Clone even if an experiment already has the repository"},
+	{Long: "--ssh", Desc: "Clone over SSH (git@host:owner/repo.git) instead of https"},
+	{Long: "--branch", Short: "-b", Arg: "name", Desc: "Clone this branch instead of the default one"},
+	{Long: "--depth", Arg: "n", Desc: "Clone the last n commits (0 for the full history)"},
+	{Long: "--from-clipboard", Desc: "Start with the clipboard contents as the search"},
+	{Long: "--path", Short: "-p", Arg: "dir", Desc: "Use this experiments directory for this run only"},
+	{Long: "--profile", Arg: "name", Desc: "Use the path and shell of a profile from the config file"},
+	{Long: "--list", Short: "-l", Desc: "List experiment names and exit"},
+	{Long: "--json", Desc: "Print --list as a JSON array of entries"},
+	{Long: "--stats", Desc: "Print a report of experiment activity and disk usage"},
+	{Long: "--empty-trash", Desc: "Permanently remove deleted experiments"},
+	{Long: "--config", Arg: "command", Choices: []string{"show"}, Desc: "Print the config file, path and shell in effect and where they come from"},
+	{Long: "--prune", Desc: "Move empty (and, with --older-than, stale) experiments to the trash"},
+	{Long: "--older-than", Arg: "age", Desc: "With --prune, also take experiments unchanged for this long, e.g. 90d"},
+	{Long: "--current", Desc: "Print the name of the experiment the current directory is in"},
+	{Long: "--grep", Arg: "pattern", Desc: "Only list experiments with files matching a regular expression"},
+	{Long: "--since-last", Desc: "Only list experiments changed since the last time try was browsed"},
+	{Long: "--new", Arg: "name", Desc: "Create a dated experiment and enter it, without the selector"},
+	{Long: "--last", Desc: "Enter the most recently changed experiment, without the selector"},
+	{Long: "--scratch", Desc: "Enter a throwaway experiment that is removed on exit"},
+	{Long: "--editor", Short: "-e", Desc: "Open the pick in the editor instead of a shell"},
+	{Long: "--root", Desc: "Open a shell in the base path itself"},
+	{Long: "--open-url", Arg: "name", Desc: "Open a cloned experiment's upstream page"},
+	{Long: "--run", Desc: "Run the project type's configured command in the selection"},
+	{Long: "--loop", Desc: "Return to the list after acting on each pick"},
+	{Long: "--only-repos", Desc: "Only list git checkouts"},
+	{Long: "--only-scratch", Desc: "Only list experiments that aren't git checkouts"},
+	{Long: "--ascii", Desc: "Use ASCII markers instead of emoji"},
+	{Long: "--sort", Arg: "mode", Choices: sortModes, Desc: "Order by score, name, created or accessed"},
+	{Long: "--no-touch", Desc: "Don't update the access time of the selection"},
+	{Long: "--template", Arg: "name", Desc: "Copy this template into new experiments"},
+	{Long: "--git", Desc: "Run git init in new experiments"},
+	{Long: "--no-git", Desc: "Don't run git init in new experiments"},
+	{Long: "--gitignore", Arg: "lang", Choices: gitignoreLangs, Desc: "Add a starter .gitignore to new experiments"},
+	{Long: "--from-file", Arg: "file", Desc: "Browse the paths listed in a file instead of the base path"},
+	{Long: "--yes", Short: "-y", Desc: "Accept the first-run defaults, or what --prune found, without prompting"},
+	{Long: "--setup", Desc: "Re-run the first-run setup questions"},
+	{Long: "--completions", Arg: "shell", Choices: completionShells, Desc: "Print a shell completion script"},
+	{Long: "--completion", Arg: "shell", Choices: completionShells, Desc: "Same as --completions"},
+}
+
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// cliSubcommands are positional words with a special meaning, completed alongside experiment names
+var cliSubcommands = []string{"today", "init", "setup"}
+
+// completionScript returns the completion script for the given shell.
+// Experiment names are completed dynamically by calling `try --list`.
+func completionScript(shell string) (string, error) {
+	switch shell {
+	case "bash":
+		return bashCompletion(), nil
+	case "zsh":
+		return zshCompletion(), nil
+	case "fish":
+		return fishCompletion(), nil
+	default:
+		return "", fmt.Errorf("unsupported shell for completions: %s (use bash, zsh or fish)", shell)
+	}
+}
+
+func (f cliFlag) names() []string {
+	if f.Short != "" {
+		return []string{f.Long, f.Short}
+	}
+	return []string{f.Long}
+}
+
+func bashCompletion() string {
+	var words []string
+	for _, f := range cliFlags {
+		words = append(words, f.names()...)
+	}
+
+	var b strings.Builder
+	b.WriteString("# bash completion for try\n")
+	b.WriteString("_try() {\n")
+	b.WriteString("    local cur prev\n")
+	b.WriteString("    cur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("    prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	b.WriteString("    case \"$prev\" in\n")
+	for _, f := range cliFlags {
+		if f.Arg == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "        %s)\n", strings.Join(f.names(), "|"))
+		switch {
+		case len(f.Choices) > 0:
+			fmt.Fprintf(&b, "            COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(f.Choices, " "))
+		case f.Arg == "dir":
+			b.WriteString("            COMPREPLY=($(compgen -d -- \"$cur\"))\n")
+		case f.Arg == "file":
+			b.WriteString("            COMPREPLY=($(compgen -f -- \"$cur\"))\n")
+		}
+		b.WriteString("            return ;;\n")
+	}
+	b.WriteString("    esac\n")
+	b.WriteString("    if [[ \"$cur\" == -* ]]; then\n")
+	fmt.Fprintf(&b, "        COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(words, " "))
+	b.WriteString("        return\n")
+	b.WriteString("    fi\n")
+	b.WriteString("    local IFS=$'\\n'\n")
+	fmt.Fprintf(&b, "    COMPREPLY=($(compgen -W \"$(printf '%%s\\n' %s; try --list 2>/dev/null)\" -- \"$cur\"))\n", strings.Join(cliSubcommands, " "))
+	b.WriteString("}\n")
+	b.WriteString("complete -F _try try\n")
+	return b.String()
+}
+
+func zshCompletion() string {
+	var b strings.Builder
+	b.WriteString("#compdef try\n")
+	b.WriteString("# zsh completion for try\n")
+	b.WriteString("_try_experiments() {\n")
+	b.WriteString("    local -a names\n")
+	fmt.Fprintf(&b, "    names=(%s ${(f)\"$(try --list 2>/dev/null)\"})\n", strings.Join(cliSubcommands, " "))
+	b.WriteString("    compadd -a names\n")
+	b.WriteString("}\n")
+	b.WriteString("_try() {\n")
+	b.WriteString("    _arguments -s \\\n")
+	for _, f := range cliFlags {
+		action := ""
+		switch {
+		case len(f.Choices) > 0:
+			action = fmt.Sprintf(":%s:(%s)", f.Arg, strings.Join(f.Choices, " "))
+		case f.Arg == "dir":
+			action = ":dir:_files -/"
+		case f.Arg == "file":
+			action = ":file:_files"
+		case f.Arg != "":
+			action = fmt.Sprintf(":%s: ", f.Arg)
+		}
+		if f.Short != "" {
+			fmt.Fprintf(&b, "        '(%s %s)'{%s,%s}'[%s]%s' \\\n", f.Short, f.Long, f.Short, f.Long, f.Desc, action)
+		} else {
+			fmt.Fprintf(&b, "        '%s[%s]%s' \\\n", f.Long, f.Desc, action)
+		}
+	}
+	b.WriteString("        '*:experiment:_try_experiments'\n")
+	b.WriteString("}\n")
+	b.WriteString("compdef _try try\n")
+	return b.String()
+}
+
+func fishCompletion() string {
+	var b strings.Builder
+	b.WriteString("# fish completion for try\n")
+	b.WriteString("complete -c try -f\n")
+	for _, f := range cliFlags {
+		line := "complete -c try"
+		if f.Short != "" {
+			line += " -s " + strings.TrimPrefix(f.Short, "-")
+		}
+		line += " -l " + strings.TrimPrefix(f.Long, "--")
+		switch {
+		case len(f.Choices) > 0:
+			line += fmt.Sprintf(" -x -a '%s'", strings.Join(f.Choices, " "))
+		case f.Arg == "dir":
+			line += " -x -a '(__fish_complete_directories)'"
+		case f.Arg == "file":
+			line += " -r -F"
+		case f.Arg != "":
+			line += " -x"
+		}
+		line += fmt.Sprintf(" -d '%s'", f.Desc)
+		b.WriteString(line + "\n")
+	}
+	fmt.Fprintf(&b, "complete -c try -a '%s' -d 'Subcommand'\n", strings.Join(cliSubcommands, " "))
+	b.WriteString("complete -c try -a '(try --list 2>/dev/null)' -d 'Experiment'\n")
+	return b.String()
+}
+
 func isatty(fd uintptr) bool {
 	// Simple check for TTY
 	var stat fs.FileInfo
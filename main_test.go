@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// testModel returns a selector over a base path holding the named experiments
+func testModel(t *testing.T, names ...string) model {
+	t.Setenv("HOME", t.TempDir())
+	base := t.TempDir()
+	for _, name := range names {
+		if err := os.Mkdir(filepath.Join(base, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return initialModel("", &Config{Path: base})
+}
+
+// typeKeys feeds text to m one key at a time, the way a terminal does
+func typeKeys(m model, text string) model {
+	for _, r := range text {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+		if r == ' ' {
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{r}}
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(model)
+	}
+	return m
+}
+
+func TestSpaceSeparatedSearch(t *testing.T) {
+	m := testModel(t, "2025-01-02-redis-cache", "2025-01-03-redis-streams")
+	m = typeKeys(m, "redis cache")
+
+	if m.searchTerm != "redis cache" {
+		t.Fatalf("searchTerm = %q, want %q", m.searchTerm, "redis cache")
+	}
+	if len(m.marked) != 0 {
+		t.Errorf("typing a space marked %d entries", len(m.marked))
+	}
+	if len(m.filteredTries) != 1 || m.filteredTries[0].Basename != "2025-01-02-redis-cache" {
+		t.Errorf("filteredTries = %v, want only 2025-01-02-redis-cache", m.filteredTries)
+	}
+}
+
+func TestCtrlSpaceMarks(t *testing.T) {
+	m := testModel(t, "2025-01-02-redis-cache")
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlAt})
+	m = updated.(model)
+
+	if len(m.markedEntries()) != 1 {
+		t.Errorf("ctrl+space marked %d entries, want 1", len(m.markedEntries()))
+	}
+	if m.searchTerm != "" {
+		t.Errorf("searchTerm = %q, want it empty", m.searchTerm)
+	}
+}
+
+func TestDateTokensNeedPrefix(t *testing.T) {
+	m := testModel(t, "2025-01-02-maybe-redis", "2025-05-03-api")
+	m = typeKeys(m, "may")
+	if len(m.filteredTries) != 1 || m.filteredTries[0].Basename != "2025-01-02-maybe-redis" {
+		t.Errorf("\"may\" matched %v, want only 2025-01-02-maybe-redis", m.filteredTries)
+	}
+
+	m = testModel(t, "2025-01-02-maybe-redis", "2025-05-03-api")
+	m = typeKeys(m, "date:may")
+	if len(m.filteredTries) != 1 || m.filteredTries[0].Basename != "2025-05-03-api" {
+		t.Errorf("\"date:may\" matched %v, want only 2025-05-03-api", m.filteredTries)
+	}
+}
+
+// benchModel returns a selector over 5000 dated experiments, as in a
+// long-lived base path
+func benchModel() *model {
+	words := []string{"redis", "cache", "api", "parser", "react", "rust", "tokio", "grpc", "kafka", "demo", "proto", "svelte", "auth", "queue", "bench"}
+	now := time.Now()
+	m := &model{sortMode: "score"}
+	for i := 0; i < 5000; i++ {
+		name := fmt.Sprintf("2025-%02d-%02d-%s-%s-%d", i%12+1, i%28+1, words[i%len(words)], words[(i/7)%len(words)], i)
+		m.tries = append(m.tries, tryEntry{
+			Name:     name,
+			Basename: name,
+			Path:     "/x/" + name,
+			CTime:    now.Add(-time.Duration(i) * time.Hour),
+			MTime:    now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	return m
+}
+
+// BenchmarkKeystroke measures filtering after each keystroke of a query
+func BenchmarkKeystroke(b *testing.B) {
+	m := benchModel()
+	query := "redis cache"
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := 1; j <= len(query); j++ {
+			m.searchTerm = query[:j]
+			m.filterTries()
+		}
+	}
+	b.ReportMetric(float64(b.Elapsed().Microseconds())/float64(b.N*len(query)), "us/keystroke")
+}
+
+func TestIncrementalFilterMatchesFullFilter(t *testing.T) {
+	m := benchModel()
+	for _, q := range []string{"r", "re", "red", "redi", "redis", "redis ", "redis c", "redis ca", "redis cac", "redis ca", "redis", "rep", "repo:", "repo:a", "x"} {
+		m.searchTerm = q
+		m.filterTries()
+		fresh := benchModel()
+		fresh.searchTerm = q
+		fresh.filterTries()
+		if len(fresh.filteredTries) != len(m.filteredTries) {
+			t.Fatalf("%q: %d results typed incrementally, %d from scratch", q, len(m.filteredTries), len(fresh.filteredTries))
+		}
+		for i := range fresh.filteredTries {
+			if fresh.filteredTries[i].Path != m.filteredTries[i].Path {
+				t.Fatalf("%q: result %d is %s typed incrementally, %s from scratch", q, i, m.filteredTries[i].Path, fresh.filteredTries[i].Path)
+			}
+		}
+	}
+}
+
+func TestTypedDeleteAcceptsSpaces(t *testing.T) {
+	m := testModel(t, "2025-01-02-redis", "2025-01-03-api")
+	m.marked = map[string]bool{m.tries[0].Path: true, m.tries[1].Path: true}
+	m.confirmDelete = true
+	m.deleteBatch = m.markedEntries()
+	m.deleteTarget = &m.deleteBatch[0]
+	m.deleteByName = true
+
+	m = typeKeys(m, "delete 2")
+	if !m.confirmDelete || m.deleteTyped != "delete 2" {
+		t.Fatalf("typed %q (confirming: %t), want \"delete 2\"", m.deleteTyped, m.confirmDelete)
+	}
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(model)
+	if len(m.tries) != 0 {
+		t.Errorf("%d experiments left after confirming, want 0", len(m.tries))
+	}
+}
+
+func TestIndexFold(t *testing.T) {
+	tests := []struct {
+		text, query string
+		start, end  int
+	}{
+		{"2025-01-02-Redis", "redis", 11, 16},
+		{"2025-01-02-redis", "REDIS", 11, 16},
+		{"İstanbul-notes", "notes", 10, 15}, // İ lowercases to two runes
+		{"ẞtraße-api", "api", 10, 13},
+		{"İstanbul-notes", "istanbul", 0, 9},
+		{"Ünïcode", "üNÏ", 0, 5},
+		{"redis", "cache", -1, -1},
+	}
+	for _, tt := range tests {
+		start, end := indexFold(tt.text, tt.query)
+		if start != tt.start || end != tt.end {
+			t.Errorf("indexFold(%q, %q) = %d, %d, want %d, %d", tt.text, tt.query, start, end, tt.start, tt.end)
+		}
+	}
+}